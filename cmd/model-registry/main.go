@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/workpi-ai/model-registry-go/pkg/registry"
+	"github.com/workpi-ai/model-registry-go/pkg/registry/server"
+)
+
+const usage = `Usage: model-registry [-config-dir dir] <command> [args]
+
+Commands:
+  versions        List installed registry versions
+  rollback        Activate the previous version and pin it
+  pin <version>   Activate an installed version and stop updates
+  unpin           Resume updates
+  version         Print the registry data versions in effect
+  snapshot        Print the loaded registry data as JSON
+  serve           Serve the registry over HTTP (see serve -h)
+  export          Print the registry as another tool's config (see export -h)
+  import <file>   Convert a /v1/models response or OpenRouter catalog to
+                  provider YAML (see import -h)
+`
+
+func main() {
+	home, _ := os.UserHomeDir()
+
+	flags := flag.NewFlagSet("model-registry", flag.ExitOnError)
+	configDir := flags.String("config-dir", filepath.Join(home, ".codev", "configs"), "registry config directory")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	args := flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	updater, err := registry.NewUpdater(*configDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "versions":
+		err = listVersions(updater)
+	case "rollback":
+		err = updater.Rollback()
+	case "pin":
+		if len(args) != 2 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		err = updater.Pin(args[1])
+	case "unpin":
+		err = updater.Unpin()
+	case "version", "snapshot":
+		err = printJSON(*configDir, args[0])
+	case "serve":
+		err = serve(*configDir, args[1:])
+	case "export":
+		err = export(*configDir, args[1:])
+	case "import":
+		err = importModels(*configDir, args[1:])
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printJSON(configDir, command string) error {
+	reg, err := registry.New(registry.Options{ConfigDir: configDir})
+	if err != nil {
+		return err
+	}
+	defer reg.Close()
+
+	var v any = reg.Version()
+	if command == "snapshot" {
+		v = reg.Snapshot()
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func serve(configDir string, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "listen address")
+	autoUpdate := flags.Bool("auto-update", false, "check for registry updates in the background")
+	watch := flags.Bool("watch", false, "reload when the config dir changes")
+	readOnly := flags.Bool("read-only", false, "never write to the config dir")
+	_ = flags.Parse(args)
+
+	reg, err := registry.New(registry.Options{
+		ConfigDir:  configDir,
+		AutoUpdate: *autoUpdate,
+		Watch:      *watch,
+		ReadOnly:   *readOnly,
+	})
+	if err != nil {
+		return err
+	}
+	defer reg.Close()
+
+	log.Printf("serving registry %s on %s", reg.Version().Current(), *addr)
+	return http.ListenAndServe(*addr, server.New(reg))
+}
+
+func export(configDir string, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", string(registry.ExportFormatLiteLLM), "litellm, openrouter or continue")
+	_ = flags.Parse(args)
+
+	reg, err := registry.New(registry.Options{ConfigDir: configDir})
+	if err != nil {
+		return err
+	}
+	defer reg.Close()
+
+	return reg.Export(os.Stdout, registry.ExportFormat(*format))
+}
+
+func importModels(configDir string, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	provider := flags.String("provider", "", "provider to import into (required)")
+	format := flags.String("format", "", "openai or openrouter (detected when empty)")
+	apiFormat := flags.String("api-format", "", "api_format of new models (default: that of the provider's models, or openai)")
+	baseURL := flags.String("base-url", "", "base_url of a new provider")
+	apiKey := flags.String("api-key", "", "environment variable holding a new provider's key")
+	maxInput := flags.Int("max-input", 0, "max_input for models listed without limits")
+	maxOutput := flags.Int("max-output", 0, "max_output for models listed without limits")
+	out := flags.String("out", "", "providers dir to write to, e.g. an overrides dir")
+	dryRun := flags.Bool("dry-run", false, "print the diff without writing")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 || *provider == "" || (*out == "" && !*dryRun) {
+		return fmt.Errorf("usage: import -provider name (-out dir | -dry-run) [flags] file")
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var key string
+	if *apiKey != "" {
+		key = "env:" + *apiKey
+	}
+
+	reg, err := registry.New(registry.Options{ConfigDir: configDir})
+	if err != nil {
+		return err
+	}
+	defer reg.Close()
+
+	result, err := reg.Import(data, registry.ImportOptions{
+		Format:    registry.ImportFormat(*format),
+		Provider:  registry.Provider{Name: *provider, BaseURL: *baseURL, APIKey: key},
+		APIFormat: registry.APIFormat(*apiFormat),
+		Context:   registry.Context{MaxInput: *maxInput, MaxOutput: *maxOutput},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(result.Diff())
+	if *dryRun {
+		return nil
+	}
+	return result.Write(*out)
+}
+
+func listVersions(updater *registry.Updater) error {
+	versions, err := updater.Versions()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		marker := " "
+		if v.Active {
+			marker = "*"
+			if v.Pinned {
+				marker = "P"
+			}
+		}
+		fmt.Printf("%s %-24s %-20s %s\n", marker, v.Dir, v.InstalledAt.Format(time.RFC3339), v.Version)
+	}
+
+	return nil
+}
@@ -3,12 +3,12 @@ module github.com/workpi-ai/model-registry-go
 go 1.21
 
 require (
-	github.com/workpi-ai/go-utils v0.0.3
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/google/go-github/v68 v68.0.0
 	github.com/workpi-ai/model-registry v0.1.40
+	golang.org/x/crypto v0.31.0
+	golang.org/x/sys v0.28.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require (
-	github.com/google/go-github/v68 v68.0.0 // indirect
-	github.com/google/go-querystring v1.1.0 // indirect
-)
+require github.com/google/go-querystring v1.1.0 // indirect
@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	envAWSAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	envAWSSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	envAWSSessionToken    = "AWS_SESSION_TOKEN"
+	envAWSRegion          = "AWS_REGION"
+	envAWSDefaultRegion   = "AWS_DEFAULT_REGION"
+)
+
+// BedrockConfig holds what Bedrock needs to invoke a model.
+type BedrockConfig struct {
+	// ModelID is the Bedrock modelId. It defaults to the model name.
+	ModelID string `yaml:"model_id" json:"model_id" mapstructure:"model_id"`
+	// InferenceProfiles maps a region, or a geography such as "us", "eu" or
+	// "apac", to the id or ARN of a cross-region inference profile.
+	InferenceProfiles map[string]string `yaml:"inference_profiles" json:"inference_profiles" mapstructure:"inference_profiles"`
+}
+
+// AWSCredentials are resolved credentials for signing Bedrock requests.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+}
+
+func (c *BedrockConfig) Copy() *BedrockConfig {
+	if c == nil {
+		return nil
+	}
+	return &BedrockConfig{
+		ModelID:           c.ModelID,
+		InferenceProfiles: CopyMap(c.InferenceProfiles),
+	}
+}
+
+func (c *BedrockConfig) Merge(override *BedrockConfig) {
+	SetIfNotZero(&c.ModelID, override.ModelID)
+	c.InferenceProfiles = MergeMap(c.InferenceProfiles, override.InferenceProfiles)
+}
+
+// BedrockModelID returns the modelId to invoke in region: the inference
+// profile for the region or its geography, otherwise the model id.
+func (m *Model) BedrockModelID(region string) (string, error) {
+	modelID := m.Name
+	var profiles map[string]string
+	if m.Bedrock != nil {
+		SetIfNotZero(&modelID, m.Bedrock.ModelID)
+		profiles = m.Bedrock.InferenceProfiles
+	}
+
+	if region == "" {
+		return modelID, nil
+	}
+	if profile, ok := profiles[region]; ok {
+		return profile, nil
+	}
+	if profile, ok := profiles[awsGeography(region)]; ok {
+		return profile, nil
+	}
+
+	if len(m.Regions) > 0 && !slices.Contains(m.Regions, region) {
+		return "", fmt.Errorf("model %s is not available in region %s", m.Name, region)
+	}
+	return modelID, nil
+}
+
+// awsGeography maps a region to the prefix Bedrock uses for cross-region
+// inference profiles, e.g. us-east-1 to "us", us-gov-west-1 to "us-gov" and
+// ap-northeast-1 to "apac".
+func awsGeography(region string) string {
+	if strings.HasPrefix(region, "us-gov-") {
+		return "us-gov"
+	}
+	geography, _, _ := strings.Cut(region, "-")
+	if geography == "ap" {
+		return "apac"
+	}
+	return geography
+}
+
+// ResolveAWSCredentials resolves api_key, api_secret and region, falling back
+// to the standard AWS environment variables. Only the region is required.
+func (p *Provider) ResolveAWSCredentials() (AWSCredentials, error) {
+	if p.AuthType != AuthTypeAWSCredentials {
+		return AWSCredentials{}, fmt.Errorf("provider %s: auth_type %s is not %s", p.Name, p.AuthType, AuthTypeAWSCredentials)
+	}
+
+	creds := AWSCredentials{
+		AccessKeyID:     resolveSetting(p.APIKey, envAWSAccessKeyID),
+		SecretAccessKey: resolveSetting(p.APISecret, envAWSSecretAccessKey),
+		SessionToken:    os.Getenv(envAWSSessionToken),
+		Region:          resolveSetting(p.Region, envAWSRegion, envAWSDefaultRegion),
+	}
+
+	var missing []string
+	sdkChain := p.APIKey == "" && p.APISecret == "" && creds.AccessKeyID == "" && creds.SecretAccessKey == ""
+	if creds.AccessKeyID == "" && !sdkChain {
+		missing = append(missing, "access key id")
+	}
+	if creds.SecretAccessKey == "" && !sdkChain {
+		missing = append(missing, "secret access key")
+	}
+	if creds.Region == "" {
+		missing = append(missing, "region")
+	}
+	if len(missing) > 0 {
+		return creds, fmt.Errorf("provider %s: missing AWS %s", p.Name, strings.Join(missing, ", "))
+	}
+
+	return creds, nil
+}
+
+// resolveSetting returns value, read from the environment when it is an env:
+// reference, then tries the fallbacks.
+func resolveSetting(value string, fallbacks ...string) string {
+	if name, ok := envName(value); ok {
+		value = os.Getenv(name)
+	}
+	if value != "" {
+		return value
+	}
+	for _, env := range fallbacks {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,285 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+type Constraints struct {
+	Supported     []string         `yaml:"supported" json:"supported" mapstructure:"supported"`
+	Unsupported   []string         `yaml:"unsupported" json:"unsupported" mapstructure:"unsupported"`
+	Ranges        map[string]Range `yaml:"ranges" json:"ranges" mapstructure:"ranges"`
+	Fixed         map[string]any   `yaml:"fixed" json:"fixed" mapstructure:"fixed"`
+	WithReasoning *Constraints     `yaml:"with_reasoning" json:"with_reasoning" mapstructure:"with_reasoning"`
+}
+
+type Range struct {
+	Min *float64 `yaml:"min" json:"min" mapstructure:"min"`
+	Max *float64 `yaml:"max" json:"max" mapstructure:"max"`
+}
+
+type Violation struct {
+	Param  string
+	Value  any
+	Reason string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s=%v: %s", v.Param, v.Value, v.Reason)
+}
+
+func (c *Constraints) Copy() *Constraints {
+	if c == nil {
+		return nil
+	}
+
+	return &Constraints{
+		Supported:     CopySlice(c.Supported),
+		Unsupported:   CopySlice(c.Unsupported),
+		Ranges:        CopyMap(c.Ranges),
+		Fixed:         CopyMap(c.Fixed),
+		WithReasoning: c.WithReasoning.Copy(),
+	}
+}
+
+func (c *Constraints) Merge(override *Constraints) {
+	if override == nil {
+		return
+	}
+
+	if len(override.Supported) > 0 {
+		c.Supported = CopySlice(override.Supported)
+	}
+	if len(override.Unsupported) > 0 {
+		c.Unsupported = CopySlice(override.Unsupported)
+	}
+
+	c.Ranges = MergeMap(c.Ranges, override.Ranges)
+	c.Fixed = MergeMap(c.Fixed, override.Fixed)
+
+	if override.WithReasoning != nil {
+		if c.WithReasoning == nil {
+			c.WithReasoning = override.WithReasoning.Copy()
+		} else {
+			c.WithReasoning.Merge(override.WithReasoning)
+		}
+	}
+}
+
+func (c *Constraints) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	for param, r := range c.Ranges {
+		if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+			return fmt.Errorf("constraint %s: min (%v) cannot exceed max (%v)", param, *r.Min, *r.Max)
+		}
+	}
+
+	return c.WithReasoning.Validate()
+}
+
+// effective returns the constraints in force, folding in WithReasoning when a
+// reasoning effort is requested.
+func (c *Constraints) effective(reasoning bool) *Constraints {
+	if c == nil {
+		return &Constraints{}
+	}
+
+	effective := c.Copy()
+	if reasoning && c.WithReasoning != nil {
+		// Unsupported lists accumulate; everything else is overridden.
+		unsupported := append(CopySlice(c.Unsupported), c.WithReasoning.Unsupported...)
+		effective.Merge(c.WithReasoning)
+		effective.Unsupported = unsupported
+	}
+	effective.WithReasoning = nil
+
+	return effective
+}
+
+func (c *Constraints) allows(param string) bool {
+	if slices.Contains(c.Unsupported, param) {
+		return false
+	}
+	return len(c.Supported) == 0 || slices.Contains(c.Supported, param)
+}
+
+func (c *Constraints) check(param string, value any) []Violation {
+	if !c.allows(param) {
+		return []Violation{{Param: param, Value: value, Reason: "parameter is not supported"}}
+	}
+
+	if fixed, ok := c.Fixed[param]; ok {
+		if !sameValue(fixed, value) {
+			return []Violation{{Param: param, Value: value, Reason: fmt.Sprintf("must be %v", fixed)}}
+		}
+		return nil
+	}
+
+	r, ok := c.Ranges[param]
+	if !ok {
+		return nil
+	}
+
+	number, ok := toFloat(value)
+	if !ok {
+		return []Violation{{Param: param, Value: value, Reason: "must be a number"}}
+	}
+	if r.Min != nil && number < *r.Min {
+		return []Violation{{Param: param, Value: value, Reason: fmt.Sprintf("must be >= %v", *r.Min)}}
+	}
+	if r.Max != nil && number > *r.Max {
+		return []Violation{{Param: param, Value: value, Reason: fmt.Sprintf("must be <= %v", *r.Max)}}
+	}
+
+	return nil
+}
+
+// ValidateRequest checks request parameters against the model's constraints,
+// context limits and supported reasoning efforts. Zero-valued fields are
+// treated as unset. It returns nil when the request is acceptable.
+func (m *Model) ValidateRequest(params *Parameters) []Violation {
+	if params == nil {
+		return nil
+	}
+
+	chatCompletion := m.APIs.ChatCompletion
+	if chatCompletion == nil {
+		return []Violation{{Param: "api", Reason: "chat_completion api not configured"}}
+	}
+
+	var violations []Violation
+	if params.ReasoningEffort != "" {
+		if _, err := m.requestReasoning(params.ReasoningEffort, params.MaxTokens); err != nil {
+			violations = append(violations, Violation{
+				Param:  ParamReasoningEffort,
+				Value:  params.ReasoningEffort,
+				Reason: err.Error(),
+			})
+		}
+	}
+
+	if maxOutput := chatCompletion.Context.MaxOutput; maxOutput > 0 && params.MaxTokens > maxOutput {
+		violations = append(violations, Violation{
+			Param:  ParamMaxTokens,
+			Value:  params.MaxTokens,
+			Reason: fmt.Sprintf("exceeds max_output %d", maxOutput),
+		})
+	}
+
+	constraints := chatCompletion.Constraints.effective(reasoningEnabled(params.ReasoningEffort))
+	values := params.overrides().values()
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, param := range keys {
+		violations = append(violations, constraints.check(param, values[param])...)
+	}
+
+	return violations
+}
+
+// apply drops unsupported parameters and pins fixed values in place.
+func (c *Constraints) apply(params *ParamOverrides) {
+	if !c.allows(ParamTemperature) {
+		params.Temperature = nil
+	}
+	if !c.allows(ParamTopP) {
+		params.TopP = nil
+	}
+	if !c.allows(ParamMaxTokens) {
+		params.MaxTokens = 0
+	}
+	if !c.allows(ParamReasoningEffort) {
+		params.ReasoningEffort = ""
+	}
+	for k := range params.Extra {
+		if !c.allows(k) {
+			delete(params.Extra, k)
+		}
+	}
+
+	for k, v := range c.Fixed {
+		if !params.set(k, v) {
+			if params.Extra == nil {
+				params.Extra = make(map[string]any)
+			}
+			params.Extra[k] = v
+		}
+	}
+}
+
+func (p ParamOverrides) values() map[string]any {
+	values := make(map[string]any, len(p.Extra)+4)
+	for k, v := range p.Extra {
+		values[k] = v
+	}
+	if p.Temperature != nil {
+		values[ParamTemperature] = *p.Temperature
+	}
+	if p.TopP != nil {
+		values[ParamTopP] = *p.TopP
+	}
+	if p.MaxTokens != 0 {
+		values[ParamMaxTokens] = p.MaxTokens
+	}
+	if p.ReasoningEffort != "" {
+		values[ParamReasoningEffort] = p.ReasoningEffort
+	}
+	return values
+}
+
+// set assigns a typed field by its parameter name and reports whether name
+// refers to one.
+func (p *ParamOverrides) set(name string, value any) bool {
+	switch name {
+	case ParamTemperature:
+		number, _ := toFloat(value)
+		p.Temperature = Ptr(number)
+	case ParamTopP:
+		number, _ := toFloat(value)
+		p.TopP = Ptr(number)
+	case ParamMaxTokens:
+		number, _ := toFloat(value)
+		p.MaxTokens = int(number)
+	case ParamReasoningEffort:
+		p.ReasoningEffort = fmt.Sprint(value)
+	default:
+		return false
+	}
+	return true
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func sameValue(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
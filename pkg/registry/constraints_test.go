@@ -0,0 +1,248 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const constrainedModelYAML = `
+name: reasoner
+apis:
+  chat_completion:
+    api_format: anthropic
+    context:
+      max_input: 200000
+      max_output: 64000
+    features:
+      reasoning: true
+    parameters:
+      temperature: 1.0
+      max_tokens: 32000
+    constraints:
+      unsupported:
+        - seed
+      ranges:
+        temperature:
+          min: 0
+          max: 1
+        top_p:
+          min: 0
+          max: 1
+      with_reasoning:
+        unsupported:
+          - temperature
+        ranges:
+          top_p:
+            min: 0.95
+            max: 1
+`
+
+func loadConstrainedModel(t *testing.T) *Model {
+	t.Helper()
+
+	var model Model
+	if err := yaml.Unmarshal([]byte(constrainedModelYAML), &model); err != nil {
+		t.Fatalf("unmarshal model: %v", err)
+	}
+	if err := model.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+	return &model
+}
+
+func TestModelValidateRequest(t *testing.T) {
+	model := loadConstrainedModel(t)
+
+	tests := []struct {
+		name       string
+		params     *Parameters
+		wantParams []string
+	}{
+		{
+			name:   "valid request",
+			params: &Parameters{Temperature: 0.5, TopP: 0.9, MaxTokens: 1000},
+		},
+		{
+			name:       "temperature out of range",
+			params:     &Parameters{Temperature: 1.5},
+			wantParams: []string{ParamTemperature},
+		},
+		{
+			name:       "unsupported extra",
+			params:     &Parameters{Extra: map[string]any{"seed": 7}},
+			wantParams: []string{"seed"},
+		},
+		{
+			name:       "max_tokens above max_output",
+			params:     &Parameters{MaxTokens: 100000},
+			wantParams: []string{ParamMaxTokens},
+		},
+		{
+			name:       "temperature rejected with reasoning",
+			params:     &Parameters{Temperature: 0.5, TopP: 0.9, ReasoningEffort: "high"},
+			wantParams: []string{ParamTemperature, ParamTopP},
+		},
+		{
+			name:   "temperature allowed with reasoning off",
+			params: &Parameters{Temperature: 0.5, TopP: 0.9, ReasoningEffort: "off"},
+		},
+		{
+			name:       "top_p below range",
+			params:     &Parameters{TopP: -0.1},
+			wantParams: []string{ParamTopP},
+		},
+		{
+			name:       "max_tokens too small for thinking budget",
+			params:     &Parameters{MaxTokens: 1000, ReasoningEffort: "high"},
+			wantParams: []string{ParamReasoningEffort},
+		},
+		{
+			name:       "unknown reasoning effort",
+			params:     &Parameters{ReasoningEffort: "extreme"},
+			wantParams: []string{ParamReasoningEffort},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := model.ValidateRequest(tt.params)
+
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Param)
+			}
+			if !reflect.DeepEqual(got, tt.wantParams) {
+				t.Errorf("expected violations for %v, got %v", tt.wantParams, violations)
+			}
+		})
+	}
+}
+
+func TestConstraintsFixedValue(t *testing.T) {
+	one := 1.0
+	model := newParamsModel(APIFormatOpenAI, Features{}, Parameters{MaxTokens: 1000})
+	model.APIs.ChatCompletion.Constraints = &Constraints{
+		Fixed:  map[string]any{ParamTemperature: 1},
+		Ranges: map[string]Range{ParamTopP: {Max: &one}},
+	}
+
+	violations := model.ValidateRequest(&Parameters{Temperature: 0.2})
+	if len(violations) != 1 || violations[0].Param != ParamTemperature {
+		t.Fatalf("expected fixed temperature violation, got %v", violations)
+	}
+
+	if violations := model.ValidateRequest(&Parameters{Temperature: 1}); len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+
+	params, err := BuildRequestParams(model, &ParamOverrides{Temperature: Ptr(0.2)})
+	if err != nil {
+		t.Fatalf("BuildRequestParams() failed: %v", err)
+	}
+	if params[ParamTemperature] != 1.0 {
+		t.Errorf("expected fixed temperature 1, got %v", params[ParamTemperature])
+	}
+}
+
+func TestConstraintsFixedZero(t *testing.T) {
+	model := newParamsModel(APIFormatOpenAI, Features{}, Parameters{Temperature: 0.7, MaxTokens: 1000})
+	model.APIs.ChatCompletion.Constraints = &Constraints{
+		Fixed: map[string]any{ParamTemperature: 0},
+	}
+
+	violations := model.ValidateRequest(&Parameters{Temperature: 0.7})
+	if len(violations) != 1 || violations[0].Param != ParamTemperature {
+		t.Fatalf("expected fixed temperature violation, got %v", violations)
+	}
+
+	params, err := BuildRequestParams(model, nil)
+	if err != nil {
+		t.Fatalf("BuildRequestParams() failed: %v", err)
+	}
+	if value, ok := params[ParamTemperature]; !ok || value != 0.0 {
+		t.Errorf("expected fixed temperature 0, got %v", params)
+	}
+}
+
+func TestBuildRequestParamsReasoningDisabled(t *testing.T) {
+	model := loadConstrainedModel(t)
+
+	params, err := BuildRequestParams(model, &ParamOverrides{ReasoningEffort: "none"})
+	if err != nil {
+		t.Fatalf("BuildRequestParams() failed: %v", err)
+	}
+	if params[ParamTemperature] != 1.0 {
+		t.Errorf("expected temperature to be kept with reasoning disabled, got %v", params)
+	}
+	if _, ok := params["thinking"]; ok {
+		t.Errorf("expected no thinking block, got %v", params)
+	}
+}
+
+func TestBuildRequestParamsDropsUnsupported(t *testing.T) {
+	model := loadConstrainedModel(t)
+
+	params, err := BuildRequestParams(model, &ParamOverrides{
+		ReasoningEffort: "high",
+		Extra:           map[string]any{"seed": 7, "metadata": "x"},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequestParams() failed: %v", err)
+	}
+
+	if _, ok := params[ParamTemperature]; ok {
+		t.Errorf("expected temperature to be dropped, got %v", params)
+	}
+	if _, ok := params["seed"]; ok {
+		t.Errorf("expected seed to be dropped, got %v", params)
+	}
+	if params["metadata"] != "x" {
+		t.Errorf("expected metadata to pass through, got %v", params)
+	}
+}
+
+func TestConstraintsCopyAndMerge(t *testing.T) {
+	model := loadConstrainedModel(t)
+
+	copied := model.Copy()
+	copied.APIs.ChatCompletion.Constraints.Unsupported[0] = "changed"
+	copied.APIs.ChatCompletion.Constraints.WithReasoning.Unsupported[0] = "changed"
+	if model.APIs.ChatCompletion.Constraints.Unsupported[0] != "seed" ||
+		model.APIs.ChatCompletion.Constraints.WithReasoning.Unsupported[0] != ParamTemperature {
+		t.Fatal("copy shares constraints with original")
+	}
+
+	zero := 0.0
+	model.Merge(&Model{
+		APIs: APIs{
+			ChatCompletion: &ChatCompletion{
+				Constraints: &Constraints{
+					Ranges: map[string]Range{"top_k": {Min: &zero}},
+				},
+			},
+		},
+	})
+
+	constraints := model.APIs.ChatCompletion.Constraints
+	if _, ok := constraints.Ranges["top_k"]; !ok {
+		t.Error("merge did not add top_k range")
+	}
+	if _, ok := constraints.Ranges[ParamTemperature]; !ok {
+		t.Error("merge dropped existing temperature range")
+	}
+}
+
+func TestConstraintsValidate(t *testing.T) {
+	low, high := 0.0, 1.0
+	constraints := &Constraints{
+		WithReasoning: &Constraints{
+			Ranges: map[string]Range{ParamTopP: {Min: &high, Max: &low}},
+		},
+	}
+
+	if err := constraints.Validate(); err == nil {
+		t.Fatal("expected error for inverted range")
+	}
+}
@@ -12,6 +12,12 @@ const (
 	AuthTypeAWSCredentials AuthType = "aws_credentials"
 )
 
+const (
+	AuthSchemeBearer AuthSchemeType = "bearer"
+	AuthSchemeHeader AuthSchemeType = "header"
+	AuthSchemeQuery  AuthSchemeType = "query"
+)
+
 const (
 	APIFormatOpenAI    APIFormat = "openai"
 	APIFormatAnthropic APIFormat = "anthropic"
@@ -20,6 +26,54 @@ const (
 	APIFormatBedrock   APIFormat = "bedrock"
 )
 
+const (
+	ChannelStable     UpdateChannel = "stable"
+	ChannelPrerelease UpdateChannel = "prerelease"
+)
+
+const (
+	DataSourceEmbedded DataSource = "embedded"
+	DataSourceCache    DataSource = "cache"
+	DataSourceBundle   DataSource = "bundle"
+)
+
+const (
+	ExportFormatLiteLLM    ExportFormat = "litellm"
+	ExportFormatOpenRouter ExportFormat = "openrouter"
+	ExportFormatContinue   ExportFormat = "continue"
+)
+
+const (
+	DiscoveryOpenAI DiscoveryKind = "openai"
+	DiscoveryOllama DiscoveryKind = "ollama"
+)
+
+const (
+	ImportFormatOpenAI     ImportFormat = "openai"
+	ImportFormatOpenRouter ImportFormat = "openrouter"
+)
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeUpdated ChangeKind = "updated"
+	ChangeRemoved ChangeKind = "removed"
+)
+
+const (
+	FeatureToolUse          = "tool_use"
+	FeatureThinking         = "thinking"
+	FeatureThinkingLevels   = "thinking_levels"
+	FeatureReasoning        = "reasoning"
+	FeatureStructuredOutput = "structured_output"
+	FeatureAudioInput       = "audio_input"
+	FeatureImageOutput      = "image_output"
+	FeatureImageInput       = "image_input"
+)
+
+const (
+	APIChatCompletion = "chat_completion"
+)
+
 const (
 	ProviderNameOpenAI       = "openai"
 	ProviderNameOpenAISub    = "openai-sub"
@@ -0,0 +1,244 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDiscoveryInterval = 1 * time.Minute
+	maxDiscoveryResponseSize = 10 * 1024 * 1024
+)
+
+// DiscoverySource keeps a custom provider's models in sync with what its
+// server lists. Models the provider defines take precedence.
+type DiscoverySource struct {
+	// Provider names one of Options.Providers. Its BaseURL is queried.
+	Provider string
+	// Kind defaults to DiscoveryOpenAI, which lists BaseURL/models.
+	// DiscoveryOllama lists /api/tags on the Ollama server.
+	Kind DiscoveryKind
+	// Interval defaults to DefaultDiscoveryInterval.
+	Interval time.Duration
+	// Defaults configures discovered models. Limits the server reports win;
+	// unset ones default to 128000 input and 4096 output tokens.
+	Defaults   ChatCompletion
+	HTTPClient *http.Client
+}
+
+type ollamaTags struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// discoverySources returns copies of the configured sources with defaults
+// filled in, checking that each names a custom provider with a BaseURL.
+func discoverySources(opts Options) ([]*DiscoverySource, error) {
+	sources := make([]*DiscoverySource, 0, len(opts.Discovery))
+	for _, configured := range opts.Discovery {
+		source := *configured
+
+		var provider *Provider
+		for _, p := range opts.Providers {
+			if p.Name == source.Provider {
+				provider = p
+			}
+		}
+		if provider == nil || provider.BaseURL == "" {
+			return nil, fmt.Errorf("discovery: %q is not a custom provider with a base_url", source.Provider)
+		}
+
+		switch source.Kind {
+		case "":
+			source.Kind = DiscoveryOpenAI
+		case DiscoveryOpenAI, DiscoveryOllama:
+		default:
+			return nil, fmt.Errorf("discovery: unsupported kind: %s", source.Kind)
+		}
+		if source.Interval <= 0 {
+			source.Interval = DefaultDiscoveryInterval
+		}
+		if source.HTTPClient == nil {
+			source.HTTPClient = http.DefaultClient
+		}
+
+		sources = append(sources, &source)
+	}
+	return sources, nil
+}
+
+// Discover queries every discovery source now and reloads if any reported
+// a different set of models.
+func (r *Registry) Discover(ctx context.Context) error {
+	var errs []error
+	for _, source := range r.discovery {
+		if err := r.discover(ctx, source); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) discoveryLoop(source *DiscoverySource) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(source.Interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(r.ctx, defaultRequestTimeout)
+		err := r.discover(ctx, source)
+		cancel()
+		if err != nil && r.ctx.Err() == nil {
+			slog.Warn("model discovery failed", "provider", source.Provider, "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// discover lists the source's models and reloads when they changed. On
+// failure the previous models are kept.
+func (r *Registry) discover(ctx context.Context, source *DiscoverySource) error {
+	r.discoverRunMu.Lock()
+	defer r.discoverRunMu.Unlock()
+
+	provider := r.customProvider(source.Provider)
+	models, err := source.list(ctx, provider)
+	if err != nil {
+		return fmt.Errorf("discover %s: %w", source.Provider, err)
+	}
+
+	r.discoveredMu.Lock()
+	changed := !sameModelNames(r.discovered[source.Provider], models)
+	r.discovered[source.Provider] = models
+	r.discoveredMu.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	if err = r.reload(); err != nil {
+		err = fmt.Errorf("reload registry: %w", err)
+	}
+	r.notify(ReloadDiscovery, err)
+	return err
+}
+
+func (s *DiscoverySource) list(ctx context.Context, provider *Provider) (map[string]*Model, error) {
+	base := strings.TrimSuffix(provider.BaseURL, "/")
+	url := base + "/models"
+	if s.Kind == DiscoveryOllama {
+		url = strings.TrimSuffix(base, "/v1") + "/api/tags"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if provider.APIKey != "" {
+		if apiKey, err := provider.ResolveAPIKey(); err == nil {
+			req.Header.Set(headerAuthorization, "Bearer "+apiKey)
+		}
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, url)
+	}
+
+	body := json.NewDecoder(io.LimitReader(resp.Body, maxDiscoveryResponseSize))
+	listed := make(map[string]ChatCompletion)
+	if s.Kind == DiscoveryOllama {
+		var tags ollamaTags
+		if err := body.Decode(&tags); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", url, err)
+		}
+		for _, model := range tags.Models {
+			listed[model.Name] = ChatCompletion{}
+		}
+	} else {
+		var list openAIModelList
+		if err := body.Decode(&list); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", url, err)
+		}
+		for _, entry := range list.Data {
+			listed[entry.ID] = entry.chatCompletion()
+		}
+	}
+
+	models := make(map[string]*Model, len(listed))
+	for name, reported := range listed {
+		if name == "" {
+			continue
+		}
+
+		chat := s.Defaults.Copy()
+		chat.Merge(&reported)
+		SetIfNotZero(&chat.APIFormat, APIFormatOpenAI)
+		applyImportDefaults(chat, Context{})
+
+		models[name] = &Model{Name: name, APIs: APIs{ChatCompletion: chat}}
+	}
+
+	return models, nil
+}
+
+func (r *Registry) customProvider(name string) *Provider {
+	for _, provider := range r.customProviders {
+		if provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+// mergeDiscovered adds discovered models the providers do not define.
+func (r *Registry) mergeDiscovered(providers map[string]*Provider) {
+	r.discoveredMu.Lock()
+	defer r.discoveredMu.Unlock()
+
+	for name, models := range r.discovered {
+		provider := providers[name]
+		if provider == nil {
+			continue
+		}
+		for modelName, model := range models {
+			if _, ok := provider.Models[modelName]; ok {
+				continue
+			}
+			copied := model.Copy()
+			copied.Provider = provider
+			provider.Models[modelName] = copied
+		}
+	}
+}
+
+func sameModelNames(a, b map[string]*Model) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
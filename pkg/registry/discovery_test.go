@@ -0,0 +1,170 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeModelServer struct {
+	mu     sync.Mutex
+	models []string
+	auth   string
+}
+
+func (s *fakeModelServer) set(models ...string) {
+	s.mu.Lock()
+	s.models = models
+	s.mu.Unlock()
+}
+
+func (s *fakeModelServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = r.Header.Get(headerAuthorization)
+
+	switch r.URL.Path {
+	case "/v1/models":
+		list := map[string]any{"object": "list"}
+		var data []map[string]any
+		for _, name := range s.models {
+			data = append(data, map[string]any{"id": name, "object": "model", "max_model_len": 32768})
+		}
+		list["data"] = data
+		_ = json.NewEncoder(w).Encode(list)
+	case "/api/tags":
+		var models []map[string]string
+		for _, name := range s.models {
+			models = append(models, map[string]string{"name": name})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"models": models})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	fake := &fakeModelServer{models: []string{"llama-3-8b", "configured"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	t.Setenv("LOCAL_API_KEY", "local-secret")
+	events := make(chan ReloadEvent, 10)
+	reg, err := New(Options{
+		ConfigDir: t.TempDir(),
+		Providers: []*Provider{{
+			Name:     "local",
+			Type:     ProviderTypeAPI,
+			AuthType: AuthTypeAPIKey,
+			APIKey:   "env:LOCAL_API_KEY",
+			BaseURL:  server.URL + "/v1",
+			Models: map[string]*Model{
+				"configured": {Name: "configured", APIs: APIs{ChatCompletion: &ChatCompletion{
+					APIFormat:  APIFormatOpenAI,
+					Context:    Context{MaxInput: 1000, MaxOutput: 100},
+					Parameters: Parameters{MaxTokens: 100},
+				}}},
+			},
+		}},
+		Discovery: []*DiscoverySource{{
+			Provider: "local",
+			Interval: time.Hour,
+			Defaults: ChatCompletion{Features: Features{ToolUse: true}},
+		}},
+		OnReload: func(event ReloadEvent) { events <- event },
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if event := waitReload(t, events); event.Reason != ReloadDiscovery || event.Err != nil {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+
+	model := reg.Model("local", "llama-3-8b")
+	if model == nil {
+		t.Fatal("expected discovered model")
+	}
+	chat := model.APIs.ChatCompletion
+	if chat.Context.MaxInput != 32768 || chat.Parameters.MaxTokens == 0 || !chat.Features.ToolUse || model.Provider.Name != "local" {
+		t.Errorf("unexpected discovered model: %+v", chat)
+	}
+	if got := reg.Model("local", "configured").APIs.ChatCompletion.Context.MaxInput; got != 1000 {
+		t.Errorf("configured model was replaced, max_input = %d", got)
+	}
+	if fake.auth != "Bearer local-secret" {
+		t.Errorf("Authorization = %q", fake.auth)
+	}
+
+	fake.set("qwen-2")
+	if err := reg.Discover(context.Background()); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	if reg.Model("local", "qwen-2") == nil || reg.Model("local", "llama-3-8b") != nil {
+		t.Error("expected models to follow the server")
+	}
+	if reg.Model("local", "configured") == nil {
+		t.Error("expected configured model to remain")
+	}
+
+	server.Close()
+	if err := reg.Discover(context.Background()); err == nil {
+		t.Error("expected error when the server is down")
+	}
+	if reg.Model("local", "qwen-2") == nil {
+		t.Error("expected discovered models to survive a failed discovery")
+	}
+}
+
+func TestDiscoveryOllama(t *testing.T) {
+	fake := &fakeModelServer{models: []string{"llama3:latest"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	reg, err := New(Options{
+		ConfigDir: t.TempDir(),
+		Providers: []*Provider{{Name: "ollama", BaseURL: server.URL + "/v1"}},
+		Discovery: []*DiscoverySource{{Provider: "ollama", Kind: DiscoveryOllama, Interval: time.Hour}},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if err := reg.Discover(context.Background()); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	model := reg.Model("ollama", "llama3:latest")
+	if model == nil || model.APIs.ChatCompletion.Context.MaxInput != defaultImportMaxInput {
+		t.Fatalf("unexpected model: %+v", model)
+	}
+}
+
+func TestDiscoveryRequiresCustomProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		source DiscoverySource
+	}{
+		{name: "unknown provider", source: DiscoverySource{Provider: ProviderNameOpenAI}},
+		{name: "unknown kind", source: DiscoverySource{Provider: "local", Kind: "grpc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source
+			_, err := New(Options{
+				ConfigDir: t.TempDir(),
+				Providers: []*Provider{{Name: "local", BaseURL: "http://localhost:1"}},
+				Discovery: []*DiscoverySource{&source},
+			})
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
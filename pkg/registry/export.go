@@ -0,0 +1,300 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Export renders the loaded chat models of non-oauth2 providers in another
+// tool's configuration format. Deprecated models are left out.
+func (r *Registry) Export(w io.Writer, format ExportFormat) error {
+	models := exportModels(r.Snapshot())
+
+	switch format {
+	case ExportFormatLiteLLM:
+		return writeYAML(w, exportLiteLLM(models))
+	case ExportFormatOpenRouter:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(exportOpenRouter(models))
+	case ExportFormatContinue:
+		return writeYAML(w, exportContinue(models))
+	}
+
+	return fmt.Errorf("unsupported export format: %s", format)
+}
+
+type liteLLMConfig struct {
+	ModelList []liteLLMModel `yaml:"model_list"`
+}
+
+type liteLLMModel struct {
+	ModelName string            `yaml:"model_name"`
+	Params    map[string]string `yaml:"litellm_params"`
+	Info      liteLLMModelInfo  `yaml:"model_info"`
+}
+
+type liteLLMModelInfo struct {
+	MaxInputTokens          int  `yaml:"max_input_tokens,omitempty"`
+	MaxOutputTokens         int  `yaml:"max_output_tokens,omitempty"`
+	SupportsFunctionCalling bool `yaml:"supports_function_calling,omitempty"`
+	SupportsVision          bool `yaml:"supports_vision,omitempty"`
+	SupportsReasoning       bool `yaml:"supports_reasoning,omitempty"`
+	SupportsResponseSchema  bool `yaml:"supports_response_schema,omitempty"`
+	SupportsAudioInput      bool `yaml:"supports_audio_input,omitempty"`
+}
+
+type openRouterCatalog struct {
+	Data []openRouterModel `json:"data"`
+}
+
+type openRouterModel struct {
+	ID                  string                 `json:"id"`
+	Name                string                 `json:"name"`
+	Description         string                 `json:"description,omitempty"`
+	ContextLength       int                    `json:"context_length"`
+	Architecture        openRouterArchitecture `json:"architecture"`
+	TopProvider         openRouterTopProvider  `json:"top_provider"`
+	SupportedParameters []string               `json:"supported_parameters"`
+}
+
+type openRouterArchitecture struct {
+	InputModalities  []string `json:"input_modalities"`
+	OutputModalities []string `json:"output_modalities"`
+}
+
+type openRouterTopProvider struct {
+	ContextLength       int `json:"context_length"`
+	MaxCompletionTokens int `json:"max_completion_tokens"`
+}
+
+type continueConfig struct {
+	Name    string          `yaml:"name"`
+	Version string          `yaml:"version"`
+	Schema  string          `yaml:"schema"`
+	Models  []continueModel `yaml:"models"`
+}
+
+type continueModel struct {
+	Name                     string            `yaml:"name"`
+	Provider                 string            `yaml:"provider"`
+	Model                    string            `yaml:"model"`
+	APIBase                  string            `yaml:"apiBase,omitempty"`
+	APIKey                   string            `yaml:"apiKey,omitempty"`
+	RequestOptions           map[string]any    `yaml:"requestOptions,omitempty"`
+	Roles                    []string          `yaml:"roles"`
+	Capabilities             []string          `yaml:"capabilities,omitempty"`
+	DefaultCompletionOptions continueModelOpts `yaml:"defaultCompletionOptions"`
+}
+
+type continueModelOpts struct {
+	ContextLength int `yaml:"contextLength,omitempty"`
+	MaxTokens     int `yaml:"maxTokens,omitempty"`
+}
+
+func exportModels(snapshot *Snapshot) []*Model {
+	var models []*Model
+	for _, provider := range snapshot.Providers {
+		if provider.AuthType == AuthTypeOAuth2 {
+			continue
+		}
+
+		names := make([]string, 0, len(provider.Models))
+		for name, model := range provider.Models {
+			if !model.IsDeprecated && model.APIs.ChatCompletion != nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			models = append(models, provider.Models[name])
+		}
+	}
+	return models
+}
+
+func exportLiteLLM(models []*Model) liteLLMConfig {
+	config := liteLLMConfig{ModelList: []liteLLMModel{}}
+	for _, model := range models {
+		provider, chat := model.Provider, model.APIs.ChatCompletion
+
+		params := map[string]string{"model": exportProvider(chat.APIFormat) + "/" + model.Name}
+		if chat.APIFormat == APIFormatBedrock {
+			modelID, _ := model.BedrockModelID("")
+			params["model"] = exportProvider(chat.APIFormat) + "/" + modelID
+			for key, value := range map[string]string{
+				"aws_access_key_id":     provider.APIKey,
+				"aws_secret_access_key": provider.APISecret,
+				"aws_region_name":       provider.Region,
+			} {
+				if value != "" {
+					params[key] = envReference(value, "os.environ/%s")
+				}
+			}
+		} else {
+			if base := liteLLMAPIBase(chat.APIFormat, provider.BaseURL); base != "" {
+				params["api_base"] = base
+			}
+			if provider.APIKey != "" {
+				params["api_key"] = envReference(provider.APIKey, "os.environ/%s")
+			}
+		}
+
+		config.ModelList = append(config.ModelList, liteLLMModel{
+			ModelName: provider.Name + "/" + model.Name,
+			Params:    params,
+			Info: liteLLMModelInfo{
+				MaxInputTokens:          chat.Context.MaxInput,
+				MaxOutputTokens:         chat.Context.MaxOutput,
+				SupportsFunctionCalling: chat.Features.ToolUse,
+				SupportsVision:          chat.Features.ImageInput,
+				SupportsReasoning:       chat.Features.Reasoning || chat.Features.Thinking,
+				SupportsResponseSchema:  chat.Features.StructuredOutput,
+				SupportsAudioInput:      chat.Features.AudioInput,
+			},
+		})
+	}
+	return config
+}
+
+func exportOpenRouter(models []*Model) openRouterCatalog {
+	catalog := openRouterCatalog{Data: []openRouterModel{}}
+	for _, model := range models {
+		chat := model.APIs.ChatCompletion
+
+		input, output := []string{"text"}, []string{"text"}
+		if chat.Features.ImageInput {
+			input = append(input, "image")
+		}
+		if chat.Features.AudioInput {
+			input = append(input, "audio")
+		}
+		if chat.Features.ImageOutput {
+			output = append(output, "image")
+		}
+
+		catalog.Data = append(catalog.Data, openRouterModel{
+			ID:                  model.Provider.Name + "/" + model.Name,
+			Name:                model.Name,
+			Description:         model.Provider.Description,
+			ContextLength:       chat.Context.MaxInput,
+			Architecture:        openRouterArchitecture{InputModalities: input, OutputModalities: output},
+			TopProvider:         openRouterTopProvider{ContextLength: chat.Context.MaxInput, MaxCompletionTokens: chat.Context.MaxOutput},
+			SupportedParameters: openRouterParameters(chat),
+		})
+	}
+	return catalog
+}
+
+func openRouterParameters(chat *ChatCompletion) []string {
+	params := []string{"max_tokens"}
+	for _, param := range []string{"temperature", "top_p"} {
+		if chat.Constraints.effective(false).allows(param) {
+			params = append(params, param)
+		}
+	}
+	if chat.Features.ToolUse {
+		params = append(params, "tools", "tool_choice")
+	}
+	if chat.Features.Reasoning || chat.Features.Thinking {
+		params = append(params, "reasoning")
+	}
+	if chat.Features.StructuredOutput {
+		params = append(params, "structured_outputs", "response_format")
+	}
+	return params
+}
+
+func exportContinue(models []*Model) continueConfig {
+	config := continueConfig{Name: "model-registry", Version: "1.0.0", Schema: "v1", Models: []continueModel{}}
+	for _, model := range models {
+		provider, chat := model.Provider, model.APIs.ChatCompletion
+
+		entry := continueModel{
+			Name:     provider.Name + "/" + model.Name,
+			Provider: exportProvider(chat.APIFormat),
+			Model:    model.Name,
+			APIBase:  provider.BaseURL,
+			Roles:    []string{"chat", "edit", "apply"},
+			DefaultCompletionOptions: continueModelOpts{
+				ContextLength: chat.Context.MaxInput,
+				MaxTokens:     chat.Context.MaxOutput,
+			},
+		}
+		if provider.APIKey != "" {
+			entry.APIKey = envReference(provider.APIKey, "${{ secrets.%s }}")
+		}
+		if headers := exportHeaders(provider.Headers); len(headers) > 0 {
+			entry.RequestOptions = map[string]any{"headers": headers}
+		}
+		if chat.Features.ToolUse {
+			entry.Capabilities = append(entry.Capabilities, "tool_use")
+		}
+		if chat.Features.ImageInput {
+			entry.Capabilities = append(entry.Capabilities, "image_input")
+		}
+
+		config.Models = append(config.Models, entry)
+	}
+	return config
+}
+
+// exportProvider names the client integration for an API format. LiteLLM and
+// Continue both use these names, and reach anything else through "openai".
+func exportProvider(format APIFormat) string {
+	switch format {
+	case APIFormatAnthropic, APIFormatGemini, APIFormatBedrock:
+		return string(format)
+	}
+	return "openai"
+}
+
+var apiVersionSuffix = regexp.MustCompile(`/v\d+(alpha|beta)?\d*/?$`)
+
+// liteLLMAPIBase returns the api_base for LiteLLM. Its native anthropic and
+// gemini clients add the API version themselves, so it is cut from baseURL.
+func liteLLMAPIBase(format APIFormat, baseURL string) string {
+	if format != APIFormatAnthropic && format != APIFormatGemini {
+		return baseURL
+	}
+	return apiVersionSuffix.ReplaceAllString(baseURL, "")
+}
+
+// exportHeaders returns headers without the redacted ones.
+func exportHeaders(headers map[string]string) map[string]string {
+	var kept map[string]string
+	for name, value := range headers {
+		if value == redactedValue {
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]string, len(headers))
+		}
+		kept[name] = value
+	}
+	return kept
+}
+
+// envReference formats an env: reference with the target tool's syntax and
+// passes other values through.
+func envReference(value, format string) string {
+	if name, ok := envName(value); ok {
+		return fmt.Sprintf(format, name)
+	}
+	return value
+}
+
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExport(t *testing.T) {
+	// A literal key can only come from local configuration.
+	opts := Options{ReadOnly: true, Providers: []*Provider{{Name: "secret", APIKey: "sk-literal"}}}
+	reg, err := newRegistry(opts, fstest.MapFS{
+		"mirror/provider.yaml":            {Data: []byte(testProviderYAML)},
+		"mirror/models/mirror-model.yaml": testModel(t, "mirror-model", func(m *Model) { m.APIs.ChatCompletion.Features.ToolUse = true }),
+		"mirror/models/mirror-old.yaml":   testModel(t, "mirror-old", func(m *Model) { m.IsDeprecated = true }),
+		"secret/provider.yaml":            {Data: []byte("name: secret\ntype: api\nauth_type: api_key\napi_key: SECRET_API_KEY\nbase_url: https://secret.example.com\n")},
+		"secret/models/secret-model.yaml": testModel(t, "secret-model", nil),
+		"native/provider.yaml":            {Data: []byte("name: native\ntype: api\nauth_type: api_key\napi_key: NATIVE_API_KEY\nbase_url: https://native.example.com/v1\nheaders:\n  X-Auth-Token: sk-literal\n  X-Team: eng\n")},
+		"native/models/native-model.yaml": testModel(t, "native-model", func(m *Model) { m.APIs.ChatCompletion.APIFormat = APIFormatAnthropic }),
+		"sub/provider.yaml":               {Data: []byte("name: sub\ntype: subscription\nauth_type: oauth2\n")},
+		"sub/models/sub-model.yaml":       testModel(t, "sub-model", nil),
+	})
+	if err != nil {
+		t.Fatalf("newRegistry() failed: %v", err)
+	}
+	defer reg.Close()
+
+	tests := []struct {
+		format ExportFormat
+		want   []string
+	}{
+		{format: ExportFormatLiteLLM, want: []string{
+			"model_name: mirror/mirror-model",
+			"model: openai/mirror-model",
+			"api_base: https://mirror.example.com/v1",
+			"api_key: os.environ/MIRROR_API_KEY",
+			"api_key: REDACTED",
+			"api_base: https://native.example.com\n",
+			"max_input_tokens: 1000",
+			"supports_function_calling: true",
+		}},
+		{format: ExportFormatOpenRouter, want: []string{
+			`"id": "mirror/mirror-model"`,
+			`"context_length": 1000`,
+			`"max_completion_tokens": 100`,
+			`"tools"`,
+		}},
+		{format: ExportFormatContinue, want: []string{
+			"name: mirror/mirror-model",
+			"provider: openai",
+			"apiBase: https://mirror.example.com/v1",
+			"apiKey: ${{ secrets.MIRROR_API_KEY }}",
+			"contextLength: 1000",
+			"- tool_use",
+			"apiBase: https://native.example.com/v1",
+			"X-Team: eng",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := reg.Export(&buf, tt.format); err != nil {
+				t.Fatalf("Export() failed: %v", err)
+			}
+			out := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range []string{"mirror-old", "sub-model", "sk-literal", "X-Auth-Token"} {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+
+	if err := reg.Export(&bytes.Buffer{}, "unknown"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestExportEmbedded(t *testing.T) {
+	reg, err := New(Options{ConfigDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	for _, format := range []ExportFormat{ExportFormatLiteLLM, ExportFormatOpenRouter, ExportFormatContinue} {
+		var buf bytes.Buffer
+		if err := reg.Export(&buf, format); err != nil {
+			t.Fatalf("Export(%s) failed: %v", format, err)
+		}
+
+		var decoded map[string]any
+		if format == ExportFormatOpenRouter {
+			err = json.Unmarshal(buf.Bytes(), &decoded)
+		} else {
+			err = yaml.Unmarshal(buf.Bytes(), &decoded)
+		}
+		if err != nil || len(decoded) == 0 {
+			t.Errorf("Export(%s) produced invalid output: %v", format, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseModelRef splits a "provider/model" ref. Model names may themselves
+// contain slashes, as OpenRouter's do, so only the first one separates.
+func ParseModelRef(ref string) (provider, model string, err error) {
+	provider, model, ok := strings.Cut(ref, "/")
+	if !ok || provider == "" || model == "" {
+		return "", "", fmt.Errorf("invalid model ref %q, want provider/model", ref)
+	}
+	return provider, model, nil
+}
+
+// FallbackChain returns ref followed by its fallbacks, transitively, leaving
+// out deprecated and unknown models and those failing constraints.
+func (r *Registry) FallbackChain(ref string, constraints ModelFilter) ([]*Model, error) {
+	providerName, modelName, err := ParseModelRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if r.Model(providerName, modelName) == nil {
+		return nil, fmt.Errorf("model %s not found", ref)
+	}
+
+	constraints.IncludeDeprecated = false
+
+	var chain []*Model
+	seen := map[string]bool{ref: true}
+	queue := []string{ref}
+	for len(queue) > 0 {
+		ref, queue = queue[0], queue[1:]
+
+		providerName, modelName, _ := ParseModelRef(ref)
+		model := r.Model(providerName, modelName)
+		if model == nil {
+			continue
+		}
+		if constraints.Match(model) {
+			chain = append(chain, model)
+		}
+
+		for _, fallback := range model.Fallbacks {
+			if !seen[fallback] {
+				seen[fallback] = true
+				queue = append(queue, fallback)
+			}
+		}
+	}
+
+	return chain, nil
+}
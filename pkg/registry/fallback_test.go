@@ -0,0 +1,98 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFallbackChain(t *testing.T) {
+	model := func(name string, maxInput int, fallbacks ...string) *fstest.MapFile {
+		return testModel(t, name, func(m *Model) {
+			m.Fallbacks = fallbacks
+			m.APIs.ChatCompletion.Context.MaxInput = maxInput
+		})
+	}
+	provider := func(name string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(strings.ReplaceAll(testProviderYAML, "mirror", name))}
+	}
+
+	reg, err := NewFromFS(fstest.MapFS{
+		"anthropic/provider.yaml":        provider("anthropic"),
+		"anthropic/models/claude-x.yaml": model("claude-x", 2000, "bedrock/claude-x", "openrouter/anthropic/claude-x"),
+		"bedrock/provider.yaml":          provider("bedrock"),
+		"bedrock/models/claude-x.yaml":   model("claude-x", 1000, "anthropic/claude-x", "bedrock/claude-old", "missing/model"),
+		"bedrock/models/claude-old.yaml": testModel(t, "claude-old", func(m *Model) {
+			m.IsDeprecated = true
+			m.APIs.ChatCompletion.Context.MaxInput = 2000
+		}),
+		"openrouter/provider.yaml":        provider("openrouter"),
+		"openrouter/models/claude-x.yaml": model("anthropic/claude-x", 2000, "openrouter/small"),
+		"openrouter/models/small.yaml":    model("small", 500),
+	})
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	tests := []struct {
+		name        string
+		ref         string
+		constraints ModelFilter
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name: "declared order, then transitive",
+			ref:  "anthropic/claude-x",
+			want: []string{"anthropic/claude-x", "bedrock/claude-x", "openrouter/anthropic/claude-x", "openrouter/small"},
+		},
+		{
+			name:        "min context",
+			ref:         "anthropic/claude-x",
+			constraints: ModelFilter{MinContext: 2000, IncludeDeprecated: true},
+			want:        []string{"anthropic/claude-x", "openrouter/anthropic/claude-x"},
+		},
+		{
+			name: "cycle back to start",
+			ref:  "bedrock/claude-x",
+			want: []string{"bedrock/claude-x", "anthropic/claude-x", "openrouter/anthropic/claude-x", "openrouter/small"},
+		},
+		{
+			name:        "required feature",
+			ref:         "anthropic/claude-x",
+			constraints: ModelFilter{Features: []string{FeatureToolUse}},
+		},
+		{name: "unknown model", ref: "anthropic/missing", wantErr: true},
+		{name: "invalid ref", ref: "claude-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := reg.FallbackChain(tt.ref, tt.constraints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FallbackChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, model := range chain {
+				got = append(got, model.Provider.Name+"/"+model.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FallbackChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelValidateFallbacks(t *testing.T) {
+	model := &Model{Name: "m", Fallbacks: []string{"no-provider"}}
+	if err := model.Validate(); err == nil {
+		t.Error("expected error for fallback without provider")
+	}
+
+	model.Fallbacks = []string{"openrouter/anthropic/claude-x"}
+	if err := model.Validate(); err != nil {
+		t.Errorf("Validate() failed: %v", err)
+	}
+}
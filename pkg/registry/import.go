@@ -0,0 +1,381 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultImportMaxInput  = 128000
+	defaultImportMaxOutput = 4096
+)
+
+type ImportOptions struct {
+	// Format is detected from the data when empty.
+	Format ImportFormat
+	// Provider names the provider to import into. Its other fields describe
+	// a new provider and are ignored for a loaded one.
+	Provider Provider
+	// APIFormat is set on new models only. It defaults to the format most of
+	// the provider's existing models use, or openai.
+	APIFormat APIFormat
+	// Context is used for new models whose listing has no limits. It
+	// defaults to 128000 input and 4096 output tokens.
+	Context Context
+}
+
+// ImportResult is the provider an import produced, with the listed models
+// merged over its loaded ones.
+type ImportResult struct {
+	Provider *Provider
+	Changes  []ModelChange
+
+	// created is set when no provider of that name was loaded.
+	created bool
+}
+
+// ModelChange is a model the import adds, updates or, as Removed, does not
+// list. Write never deletes models.
+type ModelChange struct {
+	Model string
+	Kind  ChangeKind
+	Diff  []string
+}
+
+type openAIModelList struct {
+	Object string            `json:"object"`
+	Data   []openAIModelInfo `json:"data"`
+}
+
+// openAIModelInfo is an OpenAI list-models entry, with the limits and
+// capabilities some compatible APIs add.
+type openAIModelInfo struct {
+	ID                  string    `json:"id"`
+	ContextWindow       int       `json:"context_window"`
+	ContextLength       int       `json:"context_length"`
+	MaxContextLength    int       `json:"max_context_length"`
+	MaxModelLen         int       `json:"max_model_len"`
+	MaxOutputTokens     int       `json:"max_output_tokens"`
+	MaxCompletionTokens int       `json:"max_completion_tokens"`
+	Capabilities        *Features `json:"capabilities"`
+}
+
+func (e openAIModelInfo) chatCompletion() ChatCompletion {
+	chat := ChatCompletion{Context: Context{
+		MaxInput:  firstNonZero(e.ContextWindow, e.ContextLength, e.MaxContextLength, e.MaxModelLen),
+		MaxOutput: firstNonZero(e.MaxOutputTokens, e.MaxCompletionTokens),
+	}}
+	if e.Capabilities != nil {
+		chat.Features = *e.Capabilities
+	}
+	return chat
+}
+
+// Import converts a saved /v1/models response or OpenRouter catalog into
+// models of the named provider, merged over the ones already loaded.
+func (r *Registry) Import(data []byte, opts ImportOptions) (*ImportResult, error) {
+	if opts.Provider.Name == "" {
+		return nil, fmt.Errorf("import: provider name cannot be empty")
+	}
+
+	imported, err := parseImport(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := r.Provider(opts.Provider.Name).Copy()
+	created := provider == nil
+	if created {
+		provider = &Provider{
+			Name:     opts.Provider.Name,
+			Type:     ProviderTypeAPI,
+			AuthType: AuthTypeAPIKey,
+			APIKey:   defaultAPIKeyEnv(opts.Provider.Name),
+			Models:   make(map[string]*Model),
+		}
+		template := opts.Provider.Copy()
+		template.Models = nil
+		provider.Merge(template)
+	}
+
+	var changes []ModelChange
+	for name := range provider.Models {
+		if _, ok := imported[name]; !ok {
+			changes = append(changes, ModelChange{Model: name, Kind: ChangeRemoved})
+		}
+	}
+
+	apiFormat := opts.APIFormat
+	if apiFormat == "" {
+		apiFormat = commonAPIFormat(provider)
+	}
+	for name, model := range imported {
+		existing := provider.Models[name]
+		if existing == nil {
+			model.APIs.ChatCompletion.APIFormat = apiFormat
+			applyImportDefaults(model.APIs.ChatCompletion, opts.Context)
+			model.Provider = provider
+			provider.Models[name] = model
+			changes = append(changes, ModelChange{Model: name, Kind: ChangeAdded, Diff: diffLines(nil, modelLines(model))})
+			continue
+		}
+
+		before := modelLines(existing)
+		existing.Merge(model)
+		if after := modelLines(existing); !slices.Equal(before, after) {
+			changes = append(changes, ModelChange{Model: name, Kind: ChangeUpdated, Diff: diffLines(before, after)})
+		}
+	}
+
+	if err := provider.Validate(); err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Model < changes[j].Model })
+	return &ImportResult{Provider: provider, Changes: changes, created: created}, nil
+}
+
+func parseImport(data []byte, opts ImportOptions) (map[string]*Model, error) {
+	format := opts.Format
+	if format == "" {
+		format = detectImportFormat(data)
+	}
+
+	models := make(map[string]*Model)
+	add := func(name string, chat ChatCompletion) {
+		chat.Parameters.MaxTokens = chat.Context.MaxOutput
+		models[name] = &Model{Name: name, APIs: APIs{ChatCompletion: &chat}}
+	}
+
+	switch format {
+	case ImportFormatOpenAI:
+		var list openAIModelList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("parse openai model list: %w", err)
+		}
+		for _, entry := range list.Data {
+			add(entry.ID, entry.chatCompletion())
+		}
+	case ImportFormatOpenRouter:
+		var catalog openRouterCatalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("parse openrouter catalog: %w", err)
+		}
+		for _, entry := range catalog.Data {
+			add(entry.ID, ChatCompletion{
+				Context: Context{
+					MaxInput:  firstNonZero(entry.TopProvider.ContextLength, entry.ContextLength),
+					MaxOutput: entry.TopProvider.MaxCompletionTokens,
+				},
+				Features: openRouterFeatures(entry),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+
+	delete(models, "")
+	if len(models) == 0 {
+		return nil, fmt.Errorf("import: no models found")
+	}
+
+	return models, nil
+}
+
+// detectImportFormat tells the formats apart by the "object": "list" field
+// that OpenAI-compatible responses have and OpenRouter catalogs lack.
+func detectImportFormat(data []byte) ImportFormat {
+	var probe struct {
+		Object string `json:"object"`
+	}
+	if json.Unmarshal(data, &probe) == nil && probe.Object == "list" {
+		return ImportFormatOpenAI
+	}
+	return ImportFormatOpenRouter
+}
+
+func openRouterFeatures(entry openRouterModel) Features {
+	var features Features
+	for _, param := range entry.SupportedParameters {
+		switch param {
+		case "tools":
+			features.ToolUse = true
+		case "reasoning", "include_reasoning":
+			features.Reasoning = true
+		case "structured_outputs", "response_format":
+			features.StructuredOutput = true
+		}
+	}
+	for _, modality := range entry.Architecture.InputModalities {
+		features.ImageInput = features.ImageInput || modality == "image"
+		features.AudioInput = features.AudioInput || modality == "audio"
+	}
+	for _, modality := range entry.Architecture.OutputModalities {
+		features.ImageOutput = features.ImageOutput || modality == "image"
+	}
+	return features
+}
+
+// commonAPIFormat returns the format most of the provider's models use, or
+// openai when it has none.
+func commonAPIFormat(provider *Provider) APIFormat {
+	counts := make(map[APIFormat]int)
+	if provider != nil {
+		for _, model := range provider.Models {
+			if chat := model.APIs.ChatCompletion; chat != nil && chat.APIFormat != "" {
+				counts[chat.APIFormat]++
+			}
+		}
+	}
+
+	common := APIFormatOpenAI
+	for format, n := range counts {
+		if n > counts[common] || (n == counts[common] && format < common) {
+			common = format
+		}
+	}
+	return common
+}
+
+func applyImportDefaults(chat *ChatCompletion, defaults Context) {
+	if defaults.MaxInput == 0 {
+		defaults.MaxInput = defaultImportMaxInput
+	}
+	if defaults.MaxOutput == 0 {
+		defaults.MaxOutput = defaultImportMaxOutput
+	}
+
+	if chat.Context.MaxInput == 0 {
+		chat.Context.MaxInput = defaults.MaxInput
+	}
+	if chat.Context.MaxOutput == 0 {
+		chat.Context.MaxOutput = min(defaults.MaxOutput, chat.Context.MaxInput)
+	}
+	if chat.Parameters.MaxTokens == 0 {
+		chat.Parameters.MaxTokens = chat.Context.MaxOutput
+	}
+}
+
+func defaultAPIKeyEnv(provider string) string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(provider)) + "_API_KEY"
+}
+
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// modelLines returns the YAML lines Write stores for model.
+func modelLines(model *Model) []string {
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, model); err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// diffLines returns the lines of b marked "+ " where they are new, "- " for
+// lines of a they replace, and "  " where both agree.
+func diffLines(a, b []string) []string {
+	// common[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff = append(diff, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			diff = append(diff, "- "+a[i])
+			i++
+		default:
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+	return diff
+}
+
+// Diff renders the changes as one line per model, marked + for added, ~ for
+// updated and - for removed, each followed by the diff of its YAML.
+func (r *ImportResult) Diff() string {
+	var b strings.Builder
+
+	marks := map[ChangeKind]string{ChangeAdded: "+", ChangeUpdated: "~", ChangeRemoved: "-"}
+	for _, change := range r.Changes {
+		fmt.Fprintf(&b, "%s %s\n", marks[change.Kind], change.Model)
+		for _, line := range change.Diff {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+
+	return b.String()
+}
+
+// Write stores a file per added or updated model under dir/<provider>/models/,
+// and a provider.yaml only for a new provider.
+func (r *ImportResult) Write(dir string) error {
+	providerDir := filepath.Join(dir, r.Provider.Name)
+	modelsDir := filepath.Join(providerDir, "models")
+	if err := os.MkdirAll(modelsDir, defaultDirPerm); err != nil {
+		return err
+	}
+
+	if r.created {
+		if err := writeYAMLFile(filepath.Join(providerDir, providerYAML), r.Provider); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range r.Changes {
+		if change.Kind == ChangeRemoved {
+			continue
+		}
+		path := filepath.Join(modelsDir, modelFileName(change.Model))
+		if err := writeYAMLFile(path, r.Provider.Models[change.Model]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// modelFileName follows the registry's naming, where "/" in ids such as
+// OpenRouter's "anthropic/claude-3-opus" becomes ".".
+func modelFileName(name string) string {
+	return strings.ReplaceAll(name, "/", ".") + yamlExt
+}
+
+func writeYAMLFile(path string, v any) error {
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, v); err != nil {
+		return fmt.Errorf("encode %s: %w", path, err)
+	}
+	return os.WriteFile(path, buf.Bytes(), defaultFilePerm)
+}
@@ -0,0 +1,241 @@
+package registry
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testOpenAIModelList = `{
+  "object": "list",
+  "data": [
+    {"id": "fast-1", "object": "model", "created": 1700000000, "owned_by": "acme"},
+    {"id": "big-1", "object": "model", "owned_by": "acme", "context_window": 200000, "max_output_tokens": 16000,
+     "capabilities": {"tool_use": true}}
+  ]
+}`
+
+const testOpenRouterCatalog = `{
+  "data": [
+    {
+      "id": "mirror-model",
+      "context_length": 2000,
+      "architecture": {"input_modalities": ["text", "image"], "output_modalities": ["text"]},
+      "top_provider": {"context_length": 2000, "max_completion_tokens": 200},
+      "supported_parameters": ["tools", "temperature"]
+    },
+    {
+      "id": "vendor/new-model",
+      "context_length": 8000,
+      "top_provider": {"max_completion_tokens": 800},
+      "supported_parameters": ["reasoning"]
+    }
+  ]
+}`
+
+func TestImportOpenAIModelList(t *testing.T) {
+	reg, err := NewFromFS(fstest.MapFS{"mirror/provider.yaml": {Data: []byte(testProviderYAML)}})
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	result, err := reg.Import([]byte(testOpenAIModelList), ImportOptions{
+		Provider: Provider{Name: "acme", BaseURL: "https://acme.example.com/v1"},
+	})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	provider := result.Provider
+	if provider.APIKey != "env:ACME_API_KEY" || provider.AuthType != AuthTypeAPIKey || provider.BaseURL != "https://acme.example.com/v1" {
+		t.Errorf("unexpected provider: %+v", provider)
+	}
+
+	fast := provider.Models["fast-1"].APIs.ChatCompletion
+	if fast.Context.MaxInput != defaultImportMaxInput || fast.Context.MaxOutput != defaultImportMaxOutput || fast.APIFormat != APIFormatOpenAI {
+		t.Errorf("expected defaults for fast-1, got %+v", fast)
+	}
+	big := provider.Models["big-1"].APIs.ChatCompletion
+	if big.Context.MaxInput != 200000 || big.Parameters.MaxTokens != 16000 || !big.Features.ToolUse {
+		t.Errorf("expected listed limits for big-1, got %+v", big)
+	}
+
+	if len(result.Changes) != 2 || result.Changes[0].Kind != ChangeAdded || result.Changes[1].Kind != ChangeAdded {
+		t.Errorf("unexpected changes: %+v", result.Changes)
+	}
+	diff := result.Diff()
+	for _, want := range []string{"+ big-1", "+ fast-1", "    + name: big-1", "+       max_input: 200000"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := result.Write(dir); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	written, err := (&Loader{base: os.DirFS(dir), readOnly: true}).LoadStrict()
+	if err != nil {
+		t.Fatalf("written files do not load: %v", err)
+	}
+	if acme := written["acme"]; acme == nil || acme.BaseURL != "https://acme.example.com/v1" || len(acme.Models) != 2 {
+		t.Errorf("unexpected written provider: %+v", acme)
+	}
+}
+
+func TestImportOpenRouterCatalog(t *testing.T) {
+	base := fstest.MapFS{
+		"mirror/provider.yaml":             {Data: []byte(testProviderYAML)},
+		"mirror/models/mirror-model.yaml":  testModel(t, "mirror-model", func(m *Model) { m.APIs.ChatCompletion.Endpoint = "/custom" }),
+		"mirror/models/mirror-legacy.yaml": testModel(t, "mirror-legacy", nil),
+	}
+	reg, err := NewFromFS(base)
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	result, err := reg.Import([]byte(testOpenRouterCatalog), ImportOptions{Provider: Provider{Name: "mirror"}})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	want := map[string]ChangeKind{
+		"mirror-legacy":    ChangeRemoved,
+		"mirror-model":     ChangeUpdated,
+		"vendor/new-model": ChangeAdded,
+	}
+	if len(result.Changes) != len(want) {
+		t.Fatalf("unexpected changes: %+v", result.Changes)
+	}
+	for _, change := range result.Changes {
+		if want[change.Model] != change.Kind {
+			t.Errorf("%s: kind = %s, want %s", change.Model, change.Kind, want[change.Model])
+		}
+	}
+
+	updated := result.Provider.Models["mirror-model"].APIs.ChatCompletion
+	if updated.Endpoint != "/custom" || updated.Context.MaxInput != 2000 || !updated.Features.ToolUse || !updated.Features.ImageInput {
+		t.Errorf("expected listing merged over existing model, got %+v", updated)
+	}
+
+	diff := result.Diff()
+	for _, want := range []string{"~ mirror-model", "-       max_input: 1000", "+       max_input: 2000", "        endpoint: /custom", "+ vendor/new-model", "- mirror-legacy"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := result.Write(dir); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if _, err := os.Stat(dir + "/mirror/models/vendor.new-model.yaml"); err != nil {
+		t.Errorf("expected model file named after the id: %v", err)
+	}
+	if _, err := os.Stat(dir + "/mirror/models/mirror-legacy.yaml"); err == nil {
+		t.Error("expected no file for a removed model")
+	}
+	if _, err := os.Stat(dir + "/mirror/" + providerYAML); err == nil {
+		t.Error("expected no provider.yaml for a loaded provider")
+	}
+
+	written, err := (&Loader{base: base, overridesDir: dir, readOnly: true}).LoadStrict()
+	if err != nil {
+		t.Fatalf("written files do not load as overrides: %v", err)
+	}
+
+	model := written["mirror"].Models["vendor/new-model"]
+	if model == nil || !model.APIs.ChatCompletion.Features.Reasoning || model.APIs.ChatCompletion.Context.MaxOutput != 800 {
+		t.Errorf("unexpected written model: %+v", model)
+	}
+	if chat := written["mirror"].Models["mirror-model"].APIs.ChatCompletion; chat.Endpoint != "/custom" || chat.Context.MaxInput != 2000 {
+		t.Errorf("expected overrides to merge over the existing model, got %+v", chat)
+	}
+}
+
+func TestImportKeepsExistingSettings(t *testing.T) {
+	base := fstest.MapFS{
+		"acme/provider.yaml": {Data: []byte(`name: acme
+type: api
+auth_type: api_key
+api_key: ACME_API_KEY
+base_url: https://acme.example.com/v1
+headers:
+  Authorization: Bearer sk-literal
+  X-Team: models
+`)},
+		"acme/models/fast-1.yaml": {Data: []byte(`name: fast-1
+apis:
+  chat_completion:
+    api_format: anthropic
+    context:
+      max_input: 1000
+      max_output: 100
+    parameters:
+      max_tokens: 100
+`)},
+	}
+	reg, err := NewFromFS(base)
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	result, err := reg.Import([]byte(testOpenAIModelList), ImportOptions{Provider: Provider{Name: "acme"}})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	if format := result.Provider.Models["fast-1"].APIs.ChatCompletion.APIFormat; format != APIFormatAnthropic {
+		t.Errorf("expected existing model to keep api_format anthropic, got %s", format)
+	}
+	if format := result.Provider.Models["big-1"].APIs.ChatCompletion.APIFormat; format != APIFormatAnthropic {
+		t.Errorf("expected new model to take the provider's api_format, got %s", format)
+	}
+
+	dir := t.TempDir()
+	if err := result.Write(dir); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	written, err := (&Loader{base: base, overridesDir: dir, readOnly: true}).LoadStrict()
+	if err != nil {
+		t.Fatalf("written files do not load as overrides: %v", err)
+	}
+	acme := written["acme"]
+	if acme.APIKey != "env:ACME_API_KEY" || acme.Headers["Authorization"] != "Bearer sk-literal" {
+		t.Errorf("expected provider settings kept, got %+v", acme)
+	}
+	if acme.Models["big-1"].APIs.ChatCompletion.APIFormat != APIFormatAnthropic {
+		t.Errorf("unexpected written model: %+v", acme.Models["big-1"].APIs.ChatCompletion)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	reg, err := NewFromFS(fstest.MapFS{"mirror/provider.yaml": {Data: []byte(testProviderYAML)}})
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	tests := []struct {
+		name string
+		data string
+		opts ImportOptions
+	}{
+		{name: "no provider", data: testOpenAIModelList},
+		{name: "invalid json", data: "{", opts: ImportOptions{Provider: Provider{Name: "acme"}}},
+		{name: "empty", data: `{"object": "list", "data": []}`, opts: ImportOptions{Provider: Provider{Name: "acme"}}},
+		{name: "unknown format", data: testOpenAIModelList, opts: ImportOptions{Format: "csv", Provider: Provider{Name: "acme"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := reg.Import([]byte(tt.data), tt.opts); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
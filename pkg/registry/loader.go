@@ -1,10 +1,12 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
 
 	embed "github.com/workpi-ai/model-registry-go"
@@ -15,31 +17,203 @@ const (
 	minModelPathSegments = 3
 )
 
+const embedModulePath = "github.com/workpi-ai/model-registry"
+
+// embeddedVersion is EmbedVersion when set at build time, otherwise the
+// version of the data module recorded in the build info.
+func embeddedVersion() string {
+	if embed.EmbedVersion != "" {
+		return embed.EmbedVersion
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == embedModulePath {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+
+	return ""
+}
+
 type Loader struct {
-	configDir string
+	configDir    string
+	overridesDir string
+	// base replaces the embedded data when set.
+	base fs.FS
+	// readOnly skips the lock files, which would be created on first use.
+	readOnly bool
 }
 
 func NewLoader(configDir string) *Loader {
 	return &Loader{configDir: configDir}
 }
 
+// SetOverridesDir layers a providers tree over the cache, merged field by
+// field into existing providers.
+func (l *Loader) SetOverridesDir(dir string) {
+	l.overridesDir = dir
+}
+
 func (l *Loader) Load() (map[string]*Provider, error) {
+	return l.load(false)
+}
+
+// LoadStrict is Load, except that errors in local files are returned and
+// every provider must pass Validate.
+func (l *Loader) LoadStrict() (map[string]*Provider, error) {
+	return l.load(true)
+}
+
+func (l *Loader) load(strict bool) (map[string]*Provider, error) {
+	providers := make(map[string]*Provider)
+
+	base := l.base
+	if base == nil {
+		embedFS, err := embed.GetFS()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load embedded data: %w", err)
+		}
+		base = embedFS
+	}
+	if err := l.parseFS(providers, base); err != nil {
+		return nil, err
+	}
+
+	if err := l.parseCache(providers); err != nil && strict {
+		return nil, err
+	}
+
+	// Credential settings in registry data name environment variables.
+	for _, provider := range providers {
+		provider.referenceEnv()
+	}
+
+	// Providers that only the overrides dir defines are custom ones.
+	known := make(map[string]bool, len(providers))
+	for name := range providers {
+		known[name] = true
+	}
+
+	if stat, err := os.Stat(l.overridesDir); err == nil && stat.IsDir() {
+		if err := l.parseOverrides(providers, os.DirFS(l.overridesDir)); err != nil && strict {
+			return nil, fmt.Errorf("overrides: %w", err)
+		}
+	}
+
+	if strict {
+		for name, provider := range providers {
+			validate := provider.Validate
+			if !known[name] {
+				validate = provider.validateCustom
+			}
+			if err := validate(); err != nil {
+				return nil, fmt.Errorf("provider %s: %w", name, err)
+			}
+		}
+	}
+
+	return providers, nil
+}
+
+// referenceEnv turns api_key, api_secret and region into env: references.
+func (p *Provider) referenceEnv() {
+	for _, value := range []*string{&p.APIKey, &p.APISecret, &p.Region} {
+		if _, ok := envName(*value); !ok && *value != "" {
+			*value = envPrefix + *value
+		}
+	}
+}
+
+func (l *Loader) parseCache(providers map[string]*Provider) error {
+	if !l.cacheActive(readMetadata(filepath.Join(l.configDir, versionFile))) {
+		return nil
+	}
+
+	// Hold the read lock so a concurrent prune cannot remove the version
+	// being read. Loading still works when the lock cannot be taken.
+	if !l.readOnly {
+		if lock, err := acquireLock(context.Background(), filepath.Join(l.configDir, readLockFile), false); err == nil {
+			defer lock.Unlock()
+		}
+	}
+
+	// Resolve the active version once so an activation during the load
+	// cannot mix files from two versions.
+	return l.parseFS(providers, os.DirFS(activeProvidersDir(l.configDir)))
+}
+
+// cacheActive reports whether the cache is used. A cache older than the
+// embedded data is ignored rather than allowed to shadow newer definitions.
+func (l *Loader) cacheActive(metadata Metadata) bool {
+	if l.configDir == "" || isStale(metadata.Version) {
+		return false
+	}
+
+	stat, err := os.Stat(activeProvidersDir(l.configDir))
+	return err == nil && stat.IsDir()
+}
+
+func (l *Loader) parseOverrides(providers map[string]*Provider, fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	// Seed every directory so model-only overrides attach to the provider.
+	overrides := make(map[string]*Provider)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			overrides[entry.Name()] = &Provider{Name: entry.Name(), Models: make(map[string]*Model)}
+		}
+	}
+
+	if err := l.parseFS(overrides, fsys); err != nil {
+		return err
+	}
+
+	for name, override := range overrides {
+		if existing, ok := providers[name]; ok {
+			existing.Merge(override)
+			continue
+		}
+		if _, err := fs.Stat(fsys, name+"/"+providerYAML); err == nil {
+			providers[name] = override
+		}
+	}
+
+	return nil
+}
+
+// ValidateDir strictly parses a providers tree on top of the embedded data
+// and validates every resulting provider.
+func (l *Loader) ValidateDir(dir string) error {
 	providers := make(map[string]*Provider)
 
 	embedFS, err := embed.GetFS()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load embedded data: %w", err)
+		return fmt.Errorf("failed to load embedded data: %w", err)
 	}
 	if err := l.parseFS(providers, embedFS); err != nil {
-		return nil, err
+		return err
+	}
+	if err := l.parseFS(providers, os.DirFS(dir)); err != nil {
+		return err
 	}
 
-	localPath := filepath.Join(l.configDir, providersDir)
-	if stat, err := os.Stat(localPath); err == nil && stat.IsDir() {
-		_ = l.parseFS(providers, os.DirFS(localPath))
+	for name, provider := range providers {
+		if err := provider.Validate(); err != nil {
+			return fmt.Errorf("provider %s: %w", name, err)
+		}
 	}
 
-	return providers, nil
+	return nil
 }
 
 func (l *Loader) parseFS(providers map[string]*Provider, fsys fs.FS) error {
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	updateLockFile   = ".update.lock"
+	readLockFile     = ".lock"
+	lockPollInterval = 50 * time.Millisecond
+)
+
+var ErrUpdateInProgress = errors.New("registry update in progress in another process")
+
+// fileLock is an advisory lock on a file in ConfigDir, shared between
+// processes using the same cache.
+type fileLock struct {
+	unlock func() error
+}
+
+func (l *fileLock) Unlock() error {
+	return l.unlock()
+}
+
+// tryLock acquires the lock without waiting. It returns a nil lock when the
+// lock is held elsewhere.
+func tryLock(path string, exclusive bool) (*fileLock, error) {
+	unlock, ok, err := tryLockFile(path, exclusive)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &fileLock{unlock: unlock}, nil
+}
+
+// acquireLock waits for the lock until ctx is done.
+func acquireLock(ctx context.Context, path string, exclusive bool) (*fileLock, error) {
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+
+	for {
+		lock, err := tryLock(path, exclusive)
+		if err != nil || lock != nil {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
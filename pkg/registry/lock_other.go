@@ -0,0 +1,32 @@
+//go:build !unix && !windows
+
+package registry
+
+import (
+	"os"
+	"time"
+)
+
+// staleLockAge bounds how long a lock file left by a crashed process blocks
+// other processes.
+const staleLockAge = 10 * time.Minute
+
+// tryLockFile falls back to an exclusive create, so shared locks serialize
+// and a crashed process's lock file blocks until it is staleLockAge old.
+func tryLockFile(path string, exclusive bool) (func() error, bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, defaultFilePerm)
+	if os.IsExist(err) {
+		if stat, statErr := os.Stat(path); statErr == nil && time.Since(stat.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+		}
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	f.Close()
+
+	return func() error {
+		return os.Remove(path)
+	}, true, nil
+}
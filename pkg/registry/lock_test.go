@@ -0,0 +1,155 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), readLockFile)
+
+	shared, err := tryLock(path, false)
+	if err != nil || shared == nil {
+		t.Fatalf("tryLock(shared) = %v, %v", shared, err)
+	}
+
+	other, err := tryLock(path, false)
+	if err != nil || other == nil {
+		t.Fatalf("expected second shared lock, got %v, %v", other, err)
+	}
+
+	if lock, err := tryLock(path, true); err != nil || lock != nil {
+		t.Fatalf("expected exclusive lock to be refused while shared is held, got %v, %v", lock, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*lockPollInterval)
+	defer cancel()
+	if _, err := acquireLock(ctx, path, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected acquireLock to time out, got %v", err)
+	}
+
+	_ = shared.Unlock()
+	_ = other.Unlock()
+
+	exclusive, err := tryLock(path, true)
+	if err != nil || exclusive == nil {
+		t.Fatalf("expected exclusive lock after release, got %v, %v", exclusive, err)
+	}
+	_ = exclusive.Unlock()
+}
+
+func TestUpdaterCheck(t *testing.T) {
+	archive := createTestZip(t, map[string]string{
+		"providers/mirror/provider.yaml": testProviderYAML,
+	})
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set(headerRegistryVersion, "v1.0.0")
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	destDir := t.TempDir()
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{
+		DestDir:       destDir,
+		Source:        NewHTTPSource(server.URL),
+		CheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	lock, err := updater.lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.Check(context.Background()); !errors.Is(err, ErrUpdateInProgress) {
+		t.Fatalf("expected ErrUpdateInProgress while locked, got %v", err)
+	}
+	_ = lock.Unlock()
+
+	for i := 0; i < 2; i++ {
+		if err := updater.Check(context.Background()); err != nil {
+			t.Fatalf("Check() failed: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected recent LastCheckAt to skip the second check, got %d requests", requests)
+	}
+
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected Update to ignore CheckInterval, got %d requests", requests)
+	}
+}
+
+func TestLoadWhileActivating(t *testing.T) {
+	destDir := t.TempDir()
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: NewDirSource(t.TempDir())})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	var versions []InstalledVersion
+	for _, model := range []string{"first-model", "second-model"} {
+		installed, err := updater.stage(&Bundle{Files: map[string][]byte{
+			"providers/mirror/provider.yaml":             []byte(testProviderYAML + "description: " + model + "\n"),
+			"providers/mirror/models/" + model + ".yaml": testModel(t, model, nil).Data,
+		}}, Metadata{Version: "v9.0.0-" + model})
+		if err != nil {
+			t.Fatalf("stage() failed: %v", err)
+		}
+		versions = append(versions, installed)
+	}
+	if err := updater.activate(versions[0], false); err != nil {
+		t.Fatalf("activate() failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := updater.activate(versions[i%2], false); err != nil {
+				t.Errorf("activate() failed: %v", err)
+				return
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+
+	loader := NewLoader(destDir)
+	loader.base = fstest.MapFS{}
+	for i := 0; i < 200; i++ {
+		providers, err := loader.LoadStrict()
+		if err != nil {
+			t.Fatalf("LoadStrict() failed: %v", err)
+		}
+		provider := providers["mirror"]
+		if provider == nil {
+			t.Fatal("expected mirror provider from cache")
+		}
+		if _, ok := provider.Models[provider.Description]; !ok || len(provider.Models) != 1 {
+			t.Fatalf("load mixed versions: description %q with models %v", provider.Description, provider.Models)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+}
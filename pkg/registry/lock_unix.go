@@ -0,0 +1,34 @@
+//go:build unix
+
+package registry
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func tryLockFile(path string, exclusive bool) (func() error, bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, defaultFilePerm)
+	if err != nil {
+		return nil, false, err
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+
+	if err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return func() error {
+		defer f.Close()
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}, true, nil
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package registry
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// tryLockFile takes a byte-range lock, which Windows releases when the
+// handle is closed, so a crashed process cannot leave a stale lock behind.
+func tryLockFile(path string, exclusive bool) (func() error, bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, defaultFilePerm)
+	if err != nil {
+		return nil, false, err
+	}
+
+	flags := uint32(windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if exclusive {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+
+	handle := windows.Handle(f.Fd())
+	if err := windows.LockFileEx(handle, flags, 0, 1, 0, new(windows.Overlapped)); err != nil {
+		f.Close()
+		if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return func() error {
+		defer f.Close()
+		return windows.UnlockFileEx(handle, 0, 1, 0, new(windows.Overlapped))
+	}, true, nil
+}
@@ -3,12 +3,22 @@ package registry
 import "fmt"
 
 type Model struct {
-	Name         string   `yaml:"name" mapstructure:"name"`
-	IsDeprecated bool     `yaml:"is_deprecated" mapstructure:"is_deprecated"`
-	Agents       []string `yaml:"agents" mapstructure:"agents"`
-	APIs         APIs     `yaml:"apis" mapstructure:"apis"`
+	Name         string   `yaml:"name" json:"name" mapstructure:"name"`
+	IsDeprecated bool     `yaml:"is_deprecated" json:"is_deprecated" mapstructure:"is_deprecated"`
+	Agents       []string `yaml:"agents" json:"agents" mapstructure:"agents"`
+	Tokenizer    string   `yaml:"tokenizer" json:"tokenizer" mapstructure:"tokenizer"`
+	APIs         APIs     `yaml:"apis" json:"apis" mapstructure:"apis"`
+	// Fallbacks lists equivalent models, as "provider/model" refs, to use in
+	// order when this one is unavailable.
+	Fallbacks []string `yaml:"fallbacks" json:"fallbacks" mapstructure:"fallbacks"`
+	// Family is the canonical id of the underlying model, shared by its
+	// instances at different providers. It defaults to CanonicalModelID(Name).
+	Family string `yaml:"family" json:"family" mapstructure:"family"`
+	// Regions lists the provider regions serving the model. Empty means all.
+	Regions []string       `yaml:"regions" json:"regions" mapstructure:"regions"`
+	Bedrock *BedrockConfig `yaml:"bedrock" json:"bedrock" mapstructure:"bedrock"`
 
-	Provider *Provider `yaml:"-" mapstructure:"-"`
+	Provider *Provider `yaml:"-" json:"-" mapstructure:"-"`
 }
 
 func (m *Model) Copy() *Model {
@@ -20,6 +30,11 @@ func (m *Model) Copy() *Model {
 		Name:         m.Name,
 		IsDeprecated: m.IsDeprecated,
 		Agents:       CopySlice(m.Agents),
+		Tokenizer:    m.Tokenizer,
+		Fallbacks:    CopySlice(m.Fallbacks),
+		Family:       m.Family,
+		Regions:      CopySlice(m.Regions),
+		Bedrock:      m.Bedrock.Copy(),
 		Provider:     m.Provider,
 		APIs: APIs{
 			ChatCompletion: m.APIs.ChatCompletion.Copy(),
@@ -29,11 +44,30 @@ func (m *Model) Copy() *Model {
 	return model
 }
 
+// FamilyID returns Family, or the canonical id of Name for models that do
+// not set one.
+func (m *Model) FamilyID() string {
+	if m.Family != "" {
+		return m.Family
+	}
+	return CanonicalModelID(m.Name)
+}
+
 func (m *Model) Validate() error {
 	if m.Name == "" {
 		return fmt.Errorf("model name cannot be empty")
 	}
 
+	if _, err := GetTokenizer(m.Tokenizer); err != nil {
+		return fmt.Errorf("model %s: %w", m.Name, err)
+	}
+
+	for _, ref := range m.Fallbacks {
+		if _, _, err := ParseModelRef(ref); err != nil {
+			return fmt.Errorf("model %s: fallbacks: %w", m.Name, err)
+		}
+	}
+
 	if m.APIs.ChatCompletion != nil {
 		if m.APIs.ChatCompletion.Context.MaxInput <= 0 {
 			return fmt.Errorf("model %s: max_input must be positive", m.Name)
@@ -50,6 +84,9 @@ func (m *Model) Validate() error {
 		if m.APIs.ChatCompletion.Parameters.MaxTokens <= 0 {
 			return fmt.Errorf("model %s: max_tokens must be positive", m.Name)
 		}
+		if err := m.APIs.ChatCompletion.Constraints.Validate(); err != nil {
+			return fmt.Errorf("model %s: %w", m.Name, err)
+		}
 	}
 
 	return nil
@@ -58,10 +95,25 @@ func (m *Model) Validate() error {
 func (m *Model) Merge(override *Model) {
 	SetIfNotZero(&m.Name, override.Name)
 	SetIfNotZero(&m.IsDeprecated, override.IsDeprecated)
+	SetIfNotZero(&m.Tokenizer, override.Tokenizer)
+	SetIfNotZero(&m.Family, override.Family)
 
 	if len(override.Agents) > 0 {
 		m.Agents = CopySlice(override.Agents)
 	}
+	if len(override.Fallbacks) > 0 {
+		m.Fallbacks = CopySlice(override.Fallbacks)
+	}
+	if len(override.Regions) > 0 {
+		m.Regions = CopySlice(override.Regions)
+	}
+	if override.Bedrock != nil {
+		if m.Bedrock == nil {
+			m.Bedrock = override.Bedrock.Copy()
+		} else {
+			m.Bedrock.Merge(override.Bedrock)
+		}
+	}
 
 	if override.APIs.ChatCompletion != nil {
 		if m.APIs.ChatCompletion == nil {
@@ -73,23 +125,24 @@ func (m *Model) Merge(override *Model) {
 }
 
 type APIs struct {
-	ChatCompletion *ChatCompletion `yaml:"chat_completion" mapstructure:"chat_completion"`
+	ChatCompletion *ChatCompletion `yaml:"chat_completion" json:"chat_completion" mapstructure:"chat_completion"`
 }
 
 type ChatCompletion struct {
-	APIFormat  APIFormat  `yaml:"api_format" mapstructure:"api_format"`
-	Endpoint   string     `yaml:"endpoint" mapstructure:"endpoint"`
-	Context    Context    `yaml:"context" mapstructure:"context"`
-	Features   Features   `yaml:"features" mapstructure:"features"`
-	Parameters Parameters `yaml:"parameters" mapstructure:"parameters"`
+	APIFormat   APIFormat    `yaml:"api_format" json:"api_format" mapstructure:"api_format"`
+	Endpoint    string       `yaml:"endpoint" json:"endpoint" mapstructure:"endpoint"`
+	Context     Context      `yaml:"context" json:"context" mapstructure:"context"`
+	Features    Features     `yaml:"features" json:"features" mapstructure:"features"`
+	Parameters  Parameters   `yaml:"parameters" json:"parameters" mapstructure:"parameters"`
+	Constraints *Constraints `yaml:"constraints" json:"constraints" mapstructure:"constraints"`
 }
 
 type Parameters struct {
-	Temperature     float64        `yaml:"temperature" mapstructure:"temperature"`
-	TopP            float64        `yaml:"top_p" mapstructure:"top_p"`
-	MaxTokens       int            `yaml:"max_tokens" mapstructure:"max_tokens"`
-	ReasoningEffort string         `yaml:"reasoning_effort" mapstructure:"reasoning_effort"`
-	Extra           map[string]any `yaml:",inline" mapstructure:",remain"`
+	Temperature     float64        `yaml:"temperature" json:"temperature" mapstructure:"temperature"`
+	TopP            float64        `yaml:"top_p" json:"top_p" mapstructure:"top_p"`
+	MaxTokens       int            `yaml:"max_tokens" json:"max_tokens" mapstructure:"max_tokens"`
+	ReasoningEffort string         `yaml:"reasoning_effort" json:"reasoning_effort" mapstructure:"reasoning_effort"`
+	Extra           map[string]any `yaml:",inline" json:"-" mapstructure:",remain"`
 }
 
 func (p Parameters) Copy() Parameters {
@@ -153,7 +206,8 @@ func (c *ChatCompletion) Copy() *ChatCompletion {
 			ImageOutput:      c.Features.ImageOutput,
 			ImageInput:       c.Features.ImageInput,
 		},
-		Parameters: c.Parameters.Copy(),
+		Parameters:  c.Parameters.Copy(),
+		Constraints: c.Constraints.Copy(),
 	}
 
 	return copied
@@ -179,21 +233,29 @@ func (c *ChatCompletion) Merge(override *ChatCompletion) {
 	SetIfNotZero(&c.Features.ImageInput, override.Features.ImageInput)
 
 	c.Parameters.Merge(&override.Parameters)
+
+	if override.Constraints != nil {
+		if c.Constraints == nil {
+			c.Constraints = override.Constraints.Copy()
+		} else {
+			c.Constraints.Merge(override.Constraints)
+		}
+	}
 }
 
 type Context struct {
-	MaxInput  int `yaml:"max_input" mapstructure:"max_input"`
-	MaxOutput int `yaml:"max_output" mapstructure:"max_output"`
+	MaxInput  int `yaml:"max_input" json:"max_input" mapstructure:"max_input"`
+	MaxOutput int `yaml:"max_output" json:"max_output" mapstructure:"max_output"`
 }
 
 type Features struct {
-	ToolUse          bool     `yaml:"tool_use" mapstructure:"tool_use"`
-	Thinking         bool     `yaml:"thinking" mapstructure:"thinking"`
-	ThinkingLevels   bool     `yaml:"thinking_levels" mapstructure:"thinking_levels"`
-	Reasoning        bool     `yaml:"reasoning" mapstructure:"reasoning"`
-	ReasoningEfforts []string `yaml:"reasoning_efforts" mapstructure:"reasoning_efforts"`
-	StructuredOutput bool     `yaml:"structured_output" mapstructure:"structured_output"`
-	AudioInput       bool     `yaml:"audio_input" mapstructure:"audio_input"`
-	ImageOutput      bool     `yaml:"image_output" mapstructure:"image_output"`
-	ImageInput       bool     `yaml:"image_input" mapstructure:"image_input"`
+	ToolUse          bool     `yaml:"tool_use" json:"tool_use" mapstructure:"tool_use"`
+	Thinking         bool     `yaml:"thinking" json:"thinking" mapstructure:"thinking"`
+	ThinkingLevels   bool     `yaml:"thinking_levels" json:"thinking_levels" mapstructure:"thinking_levels"`
+	Reasoning        bool     `yaml:"reasoning" json:"reasoning" mapstructure:"reasoning"`
+	ReasoningEfforts []string `yaml:"reasoning_efforts" json:"reasoning_efforts" mapstructure:"reasoning_efforts"`
+	StructuredOutput bool     `yaml:"structured_output" json:"structured_output" mapstructure:"structured_output"`
+	AudioInput       bool     `yaml:"audio_input" json:"audio_input" mapstructure:"audio_input"`
+	ImageOutput      bool     `yaml:"image_output" json:"image_output" mapstructure:"image_output"`
+	ImageInput       bool     `yaml:"image_input" json:"image_input" mapstructure:"image_input"`
 }
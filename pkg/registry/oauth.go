@@ -0,0 +1,268 @@
+package registry
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	maxTokenResponseSize = 1024 * 1024
+	// RFC 7636 limits code verifiers to 43-128 characters.
+	minVerifierLength = 43
+	maxVerifierLength = 128
+)
+
+// OAuth describes how subscription clients obtain tokens for a provider
+// with auth_type oauth2.
+type OAuth struct {
+	AuthURL     string   `yaml:"auth_url" json:"auth_url" mapstructure:"auth_url"`
+	TokenURL    string   `yaml:"token_url" json:"token_url" mapstructure:"token_url"`
+	ClientID    string   `yaml:"client_id" json:"client_id" mapstructure:"client_id"`
+	Scopes      []string `yaml:"scopes" json:"scopes" mapstructure:"scopes"`
+	PKCE        bool     `yaml:"pkce" json:"pkce" mapstructure:"pkce"`
+	RedirectURL string   `yaml:"redirect_url" json:"redirect_url" mapstructure:"redirect_url"`
+}
+
+// OAuth2Config runs the authorization code flow for a provider. It mirrors
+// golang.org/x/oauth2.Config for public clients.
+type OAuth2Config struct {
+	ClientID    string
+	AuthURL     string
+	TokenURL    string
+	RedirectURL string
+	Scopes      []string
+	PKCE        bool
+	HTTPClient  *http.Client
+}
+
+type OAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (o *OAuth) Copy() *OAuth {
+	if o == nil {
+		return nil
+	}
+	copied := *o
+	copied.Scopes = CopySlice(o.Scopes)
+	return &copied
+}
+
+func (o *OAuth) Merge(override *OAuth) {
+	SetIfNotZero(&o.AuthURL, override.AuthURL)
+	SetIfNotZero(&o.TokenURL, override.TokenURL)
+	SetIfNotZero(&o.ClientID, override.ClientID)
+	SetIfNotZero(&o.PKCE, override.PKCE)
+	SetIfNotZero(&o.RedirectURL, override.RedirectURL)
+	if len(override.Scopes) > 0 {
+		o.Scopes = CopySlice(override.Scopes)
+	}
+}
+
+// validate checks an oauth block. Registry providers may omit it and leave
+// the flow to the client.
+func (o *OAuth) validate(authType AuthType) error {
+	if o == nil {
+		return nil
+	}
+	if authType != AuthTypeOAuth2 {
+		return fmt.Errorf("oauth is only valid when auth_type is %s", AuthTypeOAuth2)
+	}
+
+	for _, field := range [][2]string{{"auth_url", o.AuthURL}, {"token_url", o.TokenURL}} {
+		name, value := field[0], field[1]
+		if value == "" {
+			return fmt.Errorf("oauth %s is required", name)
+		}
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("oauth %s is not an absolute url: %s", name, value)
+		}
+	}
+	if o.ClientID == "" {
+		return fmt.Errorf("oauth client_id is required")
+	}
+
+	return nil
+}
+
+// validateCustom is Validate for user-defined providers, which need an oauth
+// block.
+func (p *Provider) validateCustom() error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.AuthType == AuthTypeOAuth2 && p.OAuth == nil {
+		return fmt.Errorf("provider %s: oauth is required when auth_type is %s", p.Name, AuthTypeOAuth2)
+	}
+	return nil
+}
+
+// OAuth2Config returns the provider's OAuth settings as a config that can
+// run the authorization code flow.
+func (p *Provider) OAuth2Config() (*OAuth2Config, error) {
+	if p.AuthType != AuthTypeOAuth2 || p.OAuth == nil {
+		return nil, fmt.Errorf("provider %s: no oauth configuration", p.Name)
+	}
+
+	return &OAuth2Config{
+		ClientID:    p.OAuth.ClientID,
+		AuthURL:     p.OAuth.AuthURL,
+		TokenURL:    p.OAuth.TokenURL,
+		RedirectURL: p.OAuth.RedirectURL,
+		Scopes:      CopySlice(p.OAuth.Scopes),
+		PKCE:        p.OAuth.PKCE,
+		HTTPClient:  http.DefaultClient,
+	}, nil
+}
+
+// GenerateVerifier returns a random PKCE code verifier.
+func GenerateVerifier() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// AuthCodeURL returns the URL to send the user to, with the S256 challenge
+// of verifier when PKCE is enabled.
+func (c *OAuth2Config) AuthCodeURL(state, verifier string) (string, error) {
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {c.ClientID},
+	}
+	if c.RedirectURL != "" {
+		query.Set("redirect_uri", c.RedirectURL)
+	}
+	if len(c.Scopes) > 0 {
+		query.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	if c.PKCE {
+		if err := checkVerifier(verifier); err != nil {
+			return "", err
+		}
+		challenge := sha256.Sum256([]byte(verifier))
+		query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+		query.Set("code_challenge_method", "S256")
+	}
+
+	separator := "?"
+	if strings.Contains(c.AuthURL, "?") {
+		separator = "&"
+	}
+	return c.AuthURL + separator + query.Encode(), nil
+}
+
+// Exchange trades an authorization code, and the PKCE verifier used for
+// AuthCodeURL, for a token.
+func (c *OAuth2Config) Exchange(ctx context.Context, code, verifier string) (*OAuth2Token, error) {
+	form := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	}
+	if c.RedirectURL != "" {
+		form.Set("redirect_uri", c.RedirectURL)
+	}
+	if c.PKCE {
+		if err := checkVerifier(verifier); err != nil {
+			return nil, err
+		}
+		form.Set("code_verifier", verifier)
+	}
+	return c.requestToken(ctx, form)
+}
+
+func checkVerifier(verifier string) error {
+	if verifier == "" {
+		return fmt.Errorf("pkce code verifier is required")
+	}
+	if len(verifier) < minVerifierLength || len(verifier) > maxVerifierLength {
+		return fmt.Errorf("pkce code verifier must be %d-%d characters, got %d", minVerifierLength, maxVerifierLength, len(verifier))
+	}
+	return nil
+}
+
+// Refresh obtains a new token with a refresh token.
+func (c *OAuth2Config) Refresh(ctx context.Context, refreshToken string) (*OAuth2Token, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *OAuth2Config) requestToken(ctx context.Context, form url.Values) (*OAuth2Token, error) {
+	form.Set("client_id", c.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", contentTypeJSON)
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body tokenResponse
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("read token response: %w", err)
+	}
+	if err := json.Unmarshal(data, &body); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decode token response: %w", err)
+	}
+
+	if body.Error != "" {
+		if body.ErrorDescription != "" {
+			return nil, fmt.Errorf("token request: %s: %s", body.Error, body.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token request: %s", body.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request: HTTP %d", resp.StatusCode)
+	}
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	token := &OAuth2Token{
+		AccessToken:  body.AccessToken,
+		TokenType:    body.TokenType,
+		RefreshToken: body.RefreshToken,
+	}
+	if body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
@@ -0,0 +1,224 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// newFakeTokenServer accepts code "good-code" with the verifier whose
+// challenge was sent to the auth URL, and refresh token "refresh-1".
+func newFakeTokenServer(t *testing.T, challenge *string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("client_id") != "test-client" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+			if r.Form.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != *challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "bad code or verifier"})
+				return
+			}
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access-" + r.Form.Get("grant_type"),
+			"token_type":    "Bearer",
+			"refresh_token": "refresh-1",
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOAuth2Flow(t *testing.T) {
+	var challenge string
+	server := newFakeTokenServer(t, &challenge)
+
+	provider := &Provider{
+		Name:     "sub",
+		Type:     ProviderTypeSubscription,
+		AuthType: AuthTypeOAuth2,
+		OAuth: &OAuth{
+			AuthURL:     "https://auth.example.com/authorize?audience=api",
+			TokenURL:    server.URL + "/token",
+			ClientID:    "test-client",
+			Scopes:      []string{"openid", "offline_access"},
+			PKCE:        true,
+			RedirectURL: "http://localhost:1455/callback",
+		},
+	}
+	if err := provider.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+
+	config, err := provider.OAuth2Config()
+	if err != nil {
+		t.Fatalf("OAuth2Config() failed: %v", err)
+	}
+
+	verifier, err := GenerateVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawURL, err := config.AuthCodeURL("state-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() failed: %v", err)
+	}
+	authURL, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("invalid auth url: %v", err)
+	}
+	query := authURL.Query()
+	for param, want := range map[string]string{
+		"audience":              "api",
+		"response_type":         "code",
+		"client_id":             "test-client",
+		"redirect_uri":          "http://localhost:1455/callback",
+		"scope":                 "openid offline_access",
+		"state":                 "state-1",
+		"code_challenge_method": "S256",
+	} {
+		if got := query.Get(param); got != want {
+			t.Errorf("auth url %s = %q, want %q", param, got, want)
+		}
+	}
+	challenge = query.Get("code_challenge")
+
+	token, err := config.Exchange(context.Background(), "good-code", verifier)
+	if err != nil {
+		t.Fatalf("Exchange() failed: %v", err)
+	}
+	if token.AccessToken != "access-authorization_code" || token.RefreshToken != "refresh-1" || time.Until(token.Expiry) < 59*time.Minute {
+		t.Errorf("unexpected token: %+v", token)
+	}
+
+	otherVerifier, err := GenerateVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Exchange(context.Background(), "good-code", otherVerifier); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("expected invalid_grant for wrong verifier, got %v", err)
+	}
+
+	refreshed, err := config.Refresh(context.Background(), token.RefreshToken)
+	if err != nil || refreshed.AccessToken != "access-refresh_token" {
+		t.Errorf("Refresh() = %+v, %v", refreshed, err)
+	}
+}
+
+func TestOAuth2ConfigRequiresVerifier(t *testing.T) {
+	config := &OAuth2Config{ClientID: "client", AuthURL: "https://auth.example.com/authorize", TokenURL: "http://127.0.0.1:0/token", PKCE: true}
+
+	for _, verifier := range []string{"", "too-short"} {
+		if _, err := config.AuthCodeURL("state", verifier); err == nil {
+			t.Errorf("AuthCodeURL(%q): expected error", verifier)
+		}
+		if _, err := config.Exchange(context.Background(), "code", verifier); err == nil || !strings.Contains(err.Error(), "verifier") {
+			t.Errorf("Exchange(%q): expected verifier error, got %v", verifier, err)
+		}
+	}
+
+	config.PKCE = false
+	rawURL, err := config.AuthCodeURL("state", "")
+	if err != nil || strings.Contains(rawURL, "code_challenge") {
+		t.Errorf("AuthCodeURL() without PKCE = %q, %v", rawURL, err)
+	}
+}
+
+func TestCustomOAuth2ProviderRequiresOAuth(t *testing.T) {
+	custom := &Provider{Name: "custom-sub", Type: ProviderTypeSubscription, AuthType: AuthTypeOAuth2}
+	if _, err := New(Options{ConfigDir: t.TempDir(), Providers: []*Provider{custom}}); err == nil {
+		t.Error("expected New() to reject a custom oauth2 provider without oauth")
+	}
+
+	overridesDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(overridesDir, "custom-sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(overridesDir, "custom-sub", providerYAML), []byte("name: custom-sub\ntype: subscription\nauth_type: oauth2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loader := &Loader{base: fstest.MapFS{}, overridesDir: overridesDir, readOnly: true}
+	if _, err := loader.LoadStrict(); err == nil || !strings.Contains(err.Error(), "oauth is required") {
+		t.Errorf("expected LoadStrict() to reject a custom oauth2 provider without oauth, got %v", err)
+	}
+
+	custom.OAuth = &OAuth{AuthURL: "https://auth.example.com/authorize", TokenURL: "https://auth.example.com/token", ClientID: "client"}
+	reg, err := New(Options{ConfigDir: t.TempDir(), Providers: []*Provider{custom}})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	_ = reg.Close()
+}
+
+func TestOAuthValidate(t *testing.T) {
+	valid := &OAuth{AuthURL: "https://auth.example.com/authorize", TokenURL: "https://auth.example.com/token", ClientID: "client"}
+
+	tests := []struct {
+		name     string
+		authType AuthType
+		oauth    *OAuth
+		wantErr  bool
+	}{
+		{name: "oauth2 without block", authType: AuthTypeOAuth2},
+		{name: "valid", authType: AuthTypeOAuth2, oauth: valid},
+		{name: "wrong auth type", authType: AuthTypeAPIKey, oauth: valid, wantErr: true},
+		{name: "missing client id", authType: AuthTypeOAuth2, oauth: &OAuth{AuthURL: valid.AuthURL, TokenURL: valid.TokenURL}, wantErr: true},
+		{name: "relative token url", authType: AuthTypeOAuth2, oauth: &OAuth{AuthURL: valid.AuthURL, TokenURL: "/token", ClientID: "client"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &Provider{Name: "sub", AuthType: tt.authType, APIKey: "KEY", OAuth: tt.oauth}
+			if err := provider.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := (&Provider{Name: ProviderNameOpenAISub, AuthType: AuthTypeOAuth2}).OAuth2Config(); err == nil {
+		t.Error("expected error without oauth block")
+	}
+}
+
+func TestEmbeddedOAuthProvidersValidate(t *testing.T) {
+	reg, err := New(Options{ConfigDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	for _, provider := range reg.ProviderList() {
+		if provider.AuthType != AuthTypeOAuth2 {
+			continue
+		}
+		if err := provider.Validate(); err != nil {
+			t.Errorf("embedded provider %s: %v", provider.Name, err)
+		}
+	}
+}
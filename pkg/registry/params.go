@@ -0,0 +1,285 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	ParamTemperature     = "temperature"
+	ParamTopP            = "top_p"
+	ParamMaxTokens       = "max_tokens"
+	ParamReasoningEffort = "reasoning_effort"
+)
+
+// ParamOverrides are per-request values for BuildRequestParams. Nil and zero
+// fields keep the model defaults; a set Temperature or TopP of 0 is sent.
+type ParamOverrides struct {
+	Temperature     *float64
+	TopP            *float64
+	MaxTokens       int
+	ReasoningEffort string
+	Extra           map[string]any
+}
+
+func (p *ParamOverrides) merge(override *ParamOverrides) {
+	if override == nil {
+		return
+	}
+
+	if override.Temperature != nil {
+		p.Temperature = Ptr(*override.Temperature)
+	}
+	if override.TopP != nil {
+		p.TopP = Ptr(*override.TopP)
+	}
+	SetIfNotZero(&p.MaxTokens, override.MaxTokens)
+	SetIfNotZero(&p.ReasoningEffort, override.ReasoningEffort)
+	p.Extra = MergeMap(p.Extra, override.Extra)
+}
+
+// overrides converts p, treating zero fields as unset.
+func (p Parameters) overrides() ParamOverrides {
+	params := ParamOverrides{
+		MaxTokens:       p.MaxTokens,
+		ReasoningEffort: p.ReasoningEffort,
+		Extra:           CopyMap(p.Extra),
+	}
+	if p.Temperature != 0 {
+		params.Temperature = Ptr(p.Temperature)
+	}
+	if p.TopP != 0 {
+		params.TopP = Ptr(p.TopP)
+	}
+	return params
+}
+
+// BuildRequestParams merges overrides onto the model's default parameters and
+// renders them with the field names expected by the model's APIFormat.
+// Parameters the model's constraints mark unsupported are dropped and fixed
+// values are pinned. Extra fields are passed through verbatim and win over
+// generated ones.
+func BuildRequestParams(model *Model, overrides *ParamOverrides) (map[string]any, error) {
+	if model == nil {
+		return nil, fmt.Errorf("model cannot be nil")
+	}
+
+	chatCompletion := model.APIs.ChatCompletion
+	if chatCompletion == nil {
+		return nil, fmt.Errorf("model %s: chat_completion api not configured", model.Name)
+	}
+
+	params := chatCompletion.Parameters.overrides()
+	params.merge(overrides)
+	chatCompletion.Constraints.effective(reasoningEnabled(params.ReasoningEffort)).apply(&params)
+
+	reasoning, err := model.requestReasoning(params.ReasoningEffort, params.MaxTokens)
+	if err != nil {
+		return nil, err
+	}
+	if reasoning != nil && reasoning.BudgetTokens > 0 && params.MaxTokens == 0 {
+		// The budget was taken from max_output, which becomes the limit.
+		params.MaxTokens = chatCompletion.Context.MaxOutput
+	}
+
+	var body map[string]any
+	switch chatCompletion.APIFormat {
+	case APIFormatOpenAI, "":
+		body = buildOpenAIParams(params, reasoning)
+	case APIFormatAnthropic:
+		body = buildAnthropicParams(params, reasoning)
+	case APIFormatGemini:
+		body = buildGeminiParams(params, reasoning)
+	case APIFormatCodex:
+		body = buildCodexParams(params, reasoning)
+	case APIFormatBedrock:
+		return buildBedrockParams(params, reasoning), nil
+	default:
+		return nil, fmt.Errorf("model %s: unsupported api_format: %s", model.Name, chatCompletion.APIFormat)
+	}
+
+	for k, v := range params.Extra {
+		body[k] = v
+	}
+
+	return body, nil
+}
+
+func (m *Model) requestReasoning(effort string, maxTokens int) (*ReasoningSetting, error) {
+	if effort == "" {
+		return nil, nil
+	}
+
+	level, err := ParseReasoningEffort(effort)
+	if err != nil {
+		return nil, fmt.Errorf("model %s: %w", m.Name, err)
+	}
+
+	setting, err := m.mapReasoningEffort(level, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+	if setting.Disabled {
+		return nil, nil
+	}
+
+	return setting, nil
+}
+
+func putIfNotZero[T comparable](body map[string]any, key string, value T) {
+	var zero T
+	if value != zero {
+		body[key] = value
+	}
+}
+
+func putIfNotNil[T any](body map[string]any, key string, value *T) {
+	if value != nil {
+		body[key] = *value
+	}
+}
+
+func buildOpenAIParams(params ParamOverrides, reasoning *ReasoningSetting) map[string]any {
+	body := make(map[string]any)
+	putIfNotNil(body, ParamTemperature, params.Temperature)
+	putIfNotNil(body, ParamTopP, params.TopP)
+	putIfNotZero(body, ParamMaxTokens, params.MaxTokens)
+	if reasoning != nil {
+		putIfNotZero(body, ParamReasoningEffort, reasoning.Effort)
+	}
+	return body
+}
+
+func buildAnthropicParams(params ParamOverrides, reasoning *ReasoningSetting) map[string]any {
+	body := make(map[string]any)
+	putIfNotZero(body, ParamMaxTokens, params.MaxTokens)
+	putIfNotNil(body, ParamTopP, params.TopP)
+
+	switch {
+	case reasoning != nil && reasoning.BudgetTokens > 0:
+		// Extended thinking rejects a custom temperature.
+		body["thinking"] = map[string]any{
+			"type":          "enabled",
+			"budget_tokens": reasoning.BudgetTokens,
+		}
+	case reasoning != nil && reasoning.Effort != "":
+		body["output_config"] = map[string]any{"effort": reasoning.Effort}
+		putIfNotNil(body, ParamTemperature, params.Temperature)
+	default:
+		putIfNotNil(body, ParamTemperature, params.Temperature)
+	}
+
+	return body
+}
+
+func buildGeminiParams(params ParamOverrides, reasoning *ReasoningSetting) map[string]any {
+	config := make(map[string]any)
+	putIfNotNil(config, "temperature", params.Temperature)
+	putIfNotNil(config, "topP", params.TopP)
+	putIfNotZero(config, "maxOutputTokens", params.MaxTokens)
+
+	if reasoning != nil {
+		thinking := make(map[string]any)
+		putIfNotZero(thinking, "thinkingLevel", reasoning.ThinkingLevel)
+		putIfNotZero(thinking, "thinkingBudget", reasoning.BudgetTokens)
+		if len(thinking) > 0 {
+			config["thinkingConfig"] = thinking
+		}
+	}
+
+	body := make(map[string]any)
+	if len(config) > 0 {
+		body["generationConfig"] = config
+	}
+	return body
+}
+
+func buildCodexParams(params ParamOverrides, reasoning *ReasoningSetting) map[string]any {
+	body := make(map[string]any)
+	putIfNotZero(body, "max_output_tokens", params.MaxTokens)
+
+	if reasoning != nil && reasoning.Effort != "" {
+		// Reasoning models on the Responses API reject sampling parameters.
+		body["reasoning"] = map[string]any{"effort": reasoning.Effort}
+		return body
+	}
+
+	putIfNotNil(body, ParamTemperature, params.Temperature)
+	putIfNotNil(body, ParamTopP, params.TopP)
+	return body
+}
+
+func buildBedrockParams(params ParamOverrides, reasoning *ReasoningSetting) map[string]any {
+	inference := make(map[string]any)
+	putIfNotZero(inference, "maxTokens", params.MaxTokens)
+	putIfNotNil(inference, "topP", params.TopP)
+
+	additional := make(map[string]any)
+	switch {
+	case reasoning != nil && reasoning.BudgetTokens > 0:
+		additional["thinking"] = map[string]any{
+			"type":          "enabled",
+			"budget_tokens": reasoning.BudgetTokens,
+		}
+	case reasoning != nil && reasoning.Effort != "":
+		additional[ParamReasoningEffort] = reasoning.Effort
+		putIfNotNil(inference, "temperature", params.Temperature)
+	default:
+		putIfNotNil(inference, "temperature", params.Temperature)
+	}
+
+	// Converse only accepts model-specific fields under
+	// additionalModelRequestFields.
+	for k, v := range params.Extra {
+		additional[k] = v
+	}
+
+	body := make(map[string]any)
+	if len(inference) > 0 {
+		body["inferenceConfig"] = inference
+	}
+	if len(additional) > 0 {
+		body["additionalModelRequestFields"] = additional
+	}
+	return body
+}
+
+// MarshalJSON writes Extra next to the known fields, matching the inline
+// YAML layout.
+func (p Parameters) MarshalJSON() ([]byte, error) {
+	type plain Parameters
+	if len(p.Extra) == 0 {
+		return json.Marshal(plain(p))
+	}
+
+	fields := make(map[string]any, len(p.Extra)+4)
+	for k, v := range p.Extra {
+		fields[k] = v
+	}
+	fields[ParamTemperature] = p.Temperature
+	fields[ParamTopP] = p.TopP
+	fields[ParamMaxTokens] = p.MaxTokens
+	fields[ParamReasoningEffort] = p.ReasoningEffort
+
+	return json.Marshal(fields)
+}
+
+func (p *Parameters) UnmarshalJSON(data []byte) error {
+	type plain Parameters
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, name := range []string{ParamTemperature, ParamTopP, ParamMaxTokens, ParamReasoningEffort} {
+		delete(fields, name)
+	}
+	if len(fields) > 0 {
+		p.Extra = fields
+	}
+
+	return nil
+}
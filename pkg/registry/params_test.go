@@ -0,0 +1,226 @@
+package registry
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func newParamsModel(format APIFormat, features Features, params Parameters) *Model {
+	return &Model{
+		Name: "test-model",
+		APIs: APIs{
+			ChatCompletion: &ChatCompletion{
+				APIFormat: format,
+				Context: Context{
+					MaxInput:  200000,
+					MaxOutput: 64000,
+				},
+				Features:   features,
+				Parameters: params,
+			},
+		},
+	}
+}
+
+func TestBuildRequestParams(t *testing.T) {
+	efforts := Features{Reasoning: true, ReasoningEfforts: []string{"low", "medium", "high"}}
+
+	tests := []struct {
+		name      string
+		model     *Model
+		overrides *ParamOverrides
+		want      map[string]any
+		wantError bool
+	}{
+		{
+			name: "openai defaults with override",
+			model: newParamsModel(APIFormatOpenAI, efforts, Parameters{
+				Temperature:     1.0,
+				MaxTokens:       20000,
+				ReasoningEffort: "low",
+			}),
+			overrides: &ParamOverrides{ReasoningEffort: "high", TopP: Ptr(0.9)},
+			want: map[string]any{
+				"temperature":      1.0,
+				"top_p":            0.9,
+				"max_tokens":       20000,
+				"reasoning_effort": "high",
+			},
+		},
+		{
+			name:      "openai zero temperature override",
+			model:     newParamsModel(APIFormatOpenAI, Features{}, Parameters{Temperature: 0.7, MaxTokens: 1000}),
+			overrides: &ParamOverrides{Temperature: Ptr(0.0), TopP: Ptr(0.0)},
+			want: map[string]any{
+				"temperature": 0.0,
+				"top_p":       0.0,
+				"max_tokens":  1000,
+			},
+		},
+		{
+			name: "openai extra pass-through",
+			model: newParamsModel(APIFormatOpenAI, Features{}, Parameters{
+				MaxTokens: 1000,
+				Extra:     map[string]any{"seed": 42},
+			}),
+			overrides: &ParamOverrides{Extra: map[string]any{"stream": true}},
+			want: map[string]any{
+				"max_tokens": 1000,
+				"seed":       42,
+				"stream":     true,
+			},
+		},
+		{
+			name:  "anthropic thinking budget",
+			model: newParamsModel(APIFormatAnthropic, Features{Reasoning: true}, Parameters{Temperature: 1.0, MaxTokens: 32000}),
+			overrides: &ParamOverrides{
+				ReasoningEffort: "medium",
+			},
+			want: map[string]any{
+				"max_tokens": 32000,
+				"thinking": map[string]any{
+					"type":          "enabled",
+					"budget_tokens": 16000,
+				},
+			},
+		},
+		{
+			name:      "anthropic budget within smaller max_tokens",
+			model:     newParamsModel(APIFormatAnthropic, Features{Reasoning: true}, Parameters{MaxTokens: 32000}),
+			overrides: &ParamOverrides{MaxTokens: 2000, ReasoningEffort: "high"},
+			want: map[string]any{
+				"max_tokens": 2000,
+				"thinking":   map[string]any{"type": "enabled", "budget_tokens": 1500},
+			},
+		},
+		{
+			name:      "anthropic budget from larger max_tokens",
+			model:     newParamsModel(APIFormatAnthropic, Features{Reasoning: true}, Parameters{MaxTokens: 32000}),
+			overrides: &ParamOverrides{MaxTokens: 60000, ReasoningEffort: "max"},
+			want: map[string]any{
+				"max_tokens": 60000,
+				"thinking":   map[string]any{"type": "enabled", "budget_tokens": 54000},
+			},
+		},
+		{
+			name:      "anthropic max_tokens too small for thinking",
+			model:     newParamsModel(APIFormatAnthropic, Features{Reasoning: true}, Parameters{MaxTokens: 32000}),
+			overrides: &ParamOverrides{MaxTokens: 1000, ReasoningEffort: "high"},
+			wantError: true,
+		},
+		{
+			name:  "anthropic without reasoning",
+			model: newParamsModel(APIFormatAnthropic, Features{Reasoning: true}, Parameters{Temperature: 0.5, MaxTokens: 4096}),
+			want: map[string]any{
+				"max_tokens":  4096,
+				"temperature": 0.5,
+			},
+		},
+		{
+			name:      "gemini thinking level",
+			model:     newParamsModel(APIFormatGemini, Features{Reasoning: true, Thinking: true, ThinkingLevels: true}, Parameters{Temperature: 1.0, MaxTokens: 20000}),
+			overrides: &ParamOverrides{ReasoningEffort: "high"},
+			want: map[string]any{
+				"generationConfig": map[string]any{
+					"temperature":     1.0,
+					"maxOutputTokens": 20000,
+					"thinkingConfig":  map[string]any{"thinkingLevel": "high"},
+				},
+			},
+		},
+		{
+			name:  "codex reasoning drops sampling",
+			model: newParamsModel(APIFormatCodex, efforts, Parameters{Temperature: 1.0, MaxTokens: 20000, ReasoningEffort: "medium"}),
+			want: map[string]any{
+				"max_output_tokens": 20000,
+				"reasoning":         map[string]any{"effort": "medium"},
+			},
+		},
+		{
+			name: "bedrock converse",
+			model: newParamsModel(APIFormatBedrock, Features{}, Parameters{
+				Temperature: 1.0,
+				MaxTokens:   20000,
+				Extra:       map[string]any{"top_k": 50},
+			}),
+			want: map[string]any{
+				"inferenceConfig": map[string]any{
+					"temperature": 1.0,
+					"maxTokens":   20000,
+				},
+				"additionalModelRequestFields": map[string]any{"top_k": 50},
+			},
+		},
+		{
+			name:      "unsupported reasoning effort",
+			model:     newParamsModel(APIFormatOpenAI, efforts, Parameters{MaxTokens: 1000}),
+			overrides: &ParamOverrides{ReasoningEffort: "xhigh"},
+			wantError: true,
+		},
+		{
+			name:      "unknown api format",
+			model:     newParamsModel("unknown", Features{}, Parameters{MaxTokens: 1000}),
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildRequestParams(tt.model, tt.overrides)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildRequestParamsDoesNotMutateModel(t *testing.T) {
+	model := newParamsModel(APIFormatOpenAI, Features{}, Parameters{
+		MaxTokens: 1000,
+		Extra:     map[string]any{"seed": 1},
+	})
+
+	if _, err := BuildRequestParams(model, &ParamOverrides{MaxTokens: 2000, Extra: map[string]any{"seed": 2}}); err != nil {
+		t.Fatalf("BuildRequestParams() failed: %v", err)
+	}
+
+	params := model.APIs.ChatCompletion.Parameters
+	if params.MaxTokens != 1000 || params.Extra["seed"] != 1 {
+		t.Errorf("model defaults were modified: %+v", params)
+	}
+}
+
+func TestParametersJSON(t *testing.T) {
+	params := Parameters{Temperature: 0.5, MaxTokens: 100, Extra: map[string]any{"seed": float64(7)}}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var flat map[string]any
+	if err := json.Unmarshal(data, &flat); err != nil {
+		t.Fatal(err)
+	}
+	if flat["seed"] != float64(7) || flat[ParamTemperature] != 0.5 {
+		t.Errorf("expected extra fields inline, got %s", data)
+	}
+
+	var decoded Parameters
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, params) {
+		t.Errorf("round trip mismatch: got %+v, want %+v", decoded, params)
+	}
+}
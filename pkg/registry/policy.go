@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrVersionRejected = errors.New("version rejected by update policy")
+
+// UpdatePolicy restricts which registry releases the updater installs.
+type UpdatePolicy struct {
+	// Pin installs exactly this version and nothing else.
+	Pin string
+	// Constraint is a semver range such as "~0.1", "^1.2" or ">=0.1.30 <0.2".
+	Constraint string
+	// MinVersion rejects releases older than the data schema this build
+	// understands. It defaults to the embedded data version.
+	MinVersion string
+	// Channel defaults to stable, which skips prereleases.
+	Channel UpdateChannel
+}
+
+// Release is a version a ReleaseLister can fetch.
+type Release struct {
+	Version    string
+	Prerelease bool
+	URL        string
+}
+
+// ReleaseLister is implemented by sources that can offer releases other than
+// the latest.
+type ReleaseLister interface {
+	ListReleases(ctx context.Context) ([]Release, error)
+	FetchRelease(ctx context.Context, release Release) (*Bundle, error)
+}
+
+func (p UpdatePolicy) Validate() error {
+	switch p.Channel {
+	case "", ChannelStable, ChannelPrerelease:
+	default:
+		return fmt.Errorf("unsupported update channel: %s", p.Channel)
+	}
+
+	if p.Constraint != "" {
+		if _, err := parseConstraint(p.Constraint); err != nil {
+			return err
+		}
+	}
+	if p.MinVersion != "" {
+		if _, ok := parseSemver(p.MinVersion); !ok {
+			return fmt.Errorf("invalid min version: %s", p.MinVersion)
+		}
+	}
+
+	return nil
+}
+
+func (p UpdatePolicy) withDefaults() UpdatePolicy {
+	if p.Channel == "" {
+		p.Channel = ChannelStable
+	}
+	if p.MinVersion == "" {
+		if _, ok := parseSemver(embeddedVersion()); ok {
+			p.MinVersion = embeddedVersion()
+		}
+	}
+	return p
+}
+
+// check reports why release is not allowed. Non-semver versions never match
+// a Constraint and skip MinVersion.
+func (p UpdatePolicy) check(release Release) error {
+	if p.Pin != "" {
+		if release.Version != p.Pin {
+			return fmt.Errorf("%w: %s is not pinned version %s", ErrVersionRejected, release.Version, p.Pin)
+		}
+		return nil
+	}
+
+	v, isSemver := parseSemver(release.Version)
+	if p.Channel != ChannelPrerelease && (release.Prerelease || (isSemver && v.pre != "")) {
+		return fmt.Errorf("%w: %s is a prerelease", ErrVersionRejected, release.Version)
+	}
+
+	if p.Constraint != "" && !isSemver {
+		return fmt.Errorf("%w: %s is not a semantic version", ErrVersionRejected, release.Version)
+	}
+
+	if p.MinVersion != "" && isSemver {
+		if min, _ := parseSemver(p.MinVersion); v.compare(min) < 0 {
+			return fmt.Errorf("%w: %s is older than %s", ErrVersionRejected, release.Version, p.MinVersion)
+		}
+	}
+	if p.Constraint != "" {
+		if constraint, err := parseConstraint(p.Constraint); err != nil || !constraint.matches(v) {
+			return fmt.Errorf("%w: %s does not satisfy %s", ErrVersionRejected, release.Version, p.Constraint)
+		}
+	}
+
+	return nil
+}
+
+// selectRelease returns the highest allowed release. Releases are expected
+// newest first, which decides between versions that are not semver.
+func (p UpdatePolicy) selectRelease(releases []Release) (Release, bool) {
+	var best Release
+	var bestVersion semver
+	found := false
+
+	for _, release := range releases {
+		if p.check(release) != nil {
+			continue
+		}
+
+		v, ok := parseSemver(release.Version)
+		if !found || (ok && v.compare(bestVersion) > 0) {
+			best, bestVersion, found = release, v, true
+		}
+	}
+
+	return best, found
+}
+
+// isStale reports whether a cached version is older than the embedded data.
+func isStale(cached string) bool {
+	embedded, ok := parseSemver(embeddedVersion())
+	if !ok {
+		return false
+	}
+	v, ok := parseSemver(cached)
+	return ok && v.compare(embedded) < 0
+}
@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	embed "github.com/workpi-ai/model-registry-go"
+)
+
+func TestUpdatePolicyCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  UpdatePolicy
+		release Release
+		wantErr bool
+	}{
+		{name: "default allows stable", release: Release{Version: "v0.1.40"}},
+		{name: "default allows content version", release: Release{Version: "sha256:0123456789abcdef"}},
+		{name: "stable skips flagged prerelease", release: Release{Version: "v0.2.0", Prerelease: true}, wantErr: true},
+		{name: "stable skips semver prerelease", release: Release{Version: "v0.2.0-rc.1"}, wantErr: true},
+		{name: "prerelease channel", policy: UpdatePolicy{Channel: ChannelPrerelease}, release: Release{Version: "v0.2.0-rc.1"}},
+		{name: "pin match", policy: UpdatePolicy{Pin: "v0.1.38"}, release: Release{Version: "v0.1.38"}},
+		{name: "pin mismatch", policy: UpdatePolicy{Pin: "v0.1.38"}, release: Release{Version: "v0.1.40"}, wantErr: true},
+		{name: "constraint match", policy: UpdatePolicy{Constraint: "~0.1"}, release: Release{Version: "v0.1.40"}},
+		{name: "constraint mismatch", policy: UpdatePolicy{Constraint: "~0.1"}, release: Release{Version: "v0.2.0"}, wantErr: true},
+		{name: "constraint needs semver", policy: UpdatePolicy{Constraint: "~0.1"}, release: Release{Version: "sha256:0123"}, wantErr: true},
+		{name: "below min version", policy: UpdatePolicy{MinVersion: "v0.1.40"}, release: Release{Version: "v0.1.39"}, wantErr: true},
+		{name: "min version skips content version", policy: UpdatePolicy{MinVersion: "v0.1.40"}, release: Release{Version: "sha256:0123456789abcdef"}},
+		{name: "min version with constraint needs semver", policy: UpdatePolicy{MinVersion: "v0.1.40", Constraint: ">=0.1"}, release: Release{Version: "sha256:0123"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.withDefaults().check(tt.release)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrVersionRejected) {
+				t.Errorf("expected ErrVersionRejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdatePolicyValidate(t *testing.T) {
+	invalid := []UpdatePolicy{
+		{Channel: "nightly"},
+		{Constraint: "~latest"},
+		{MinVersion: "latest"},
+	}
+	for _, policy := range invalid {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("expected error for %+v", policy)
+		}
+	}
+
+	if _, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: t.TempDir(), Policy: UpdatePolicy{Constraint: "~latest"}}); err == nil {
+		t.Error("expected NewUpdaterWithConfig() to reject invalid policy")
+	}
+}
+
+func newFakeGitHubReleases(t *testing.T, releases []Release) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/repos/"+repoOwner+"/"+repoName+"/releases", func(w http.ResponseWriter, r *http.Request) {
+		list := make([]map[string]any, 0, len(releases))
+		for _, release := range releases {
+			list = append(list, map[string]any{
+				"tag_name":    release.Version,
+				"prerelease":  release.Prerelease,
+				"zipball_url": server.URL + "/zipball/" + release.Version,
+			})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	for _, release := range releases {
+		archive := createTestZip(t, map[string]string{
+			"root/providers/mirror/provider.yaml":            testProviderYAML + "description: " + release.Version + "\n",
+			"root/providers/mirror/models/mirror-model.yaml": testModelYAML,
+		})
+		mux.HandleFunc("/zipball/"+release.Version, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(archive)
+		})
+	}
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestUpdaterSelectsReleaseByPolicy(t *testing.T) {
+	server := newFakeGitHubReleases(t, []Release{
+		{Version: "v0.3.0-rc.1", Prerelease: true},
+		{Version: "v0.2.1"},
+		{Version: "v0.1.41"},
+		{Version: "v0.1.40"},
+	})
+
+	tests := []struct {
+		name   string
+		policy UpdatePolicy
+		want   string
+	}{
+		{name: "latest stable", want: "v0.2.1"},
+		{name: "prerelease channel", policy: UpdatePolicy{Channel: ChannelPrerelease}, want: "v0.3.0-rc.1"},
+		{name: "constraint", policy: UpdatePolicy{Constraint: "~0.1"}, want: "v0.1.41"},
+		{name: "pin", policy: UpdatePolicy{Pin: "v0.1.40"}, want: "v0.1.40"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destDir := t.TempDir()
+			updater, err := NewUpdaterWithConfig(UpdaterConfig{
+				DestDir: destDir,
+				Source:  newTestGitHubSource(t, server),
+				Policy:  tt.policy,
+			})
+			if err != nil {
+				t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+			}
+
+			if err := updater.Update(context.Background()); err != nil {
+				t.Fatalf("Update() failed: %v", err)
+			}
+			if got := readMetadata(filepath.Join(destDir, versionFile)).Version; got != tt.want {
+				t.Errorf("installed %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		updater, err := NewUpdaterWithConfig(UpdaterConfig{
+			DestDir: t.TempDir(),
+			Source:  newTestGitHubSource(t, server),
+			Policy:  UpdatePolicy{Constraint: "^1.0"},
+		})
+		if err != nil {
+			t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+		}
+		if err := updater.Update(context.Background()); !errors.Is(err, ErrVersionRejected) {
+			t.Fatalf("expected ErrVersionRejected, got %v", err)
+		}
+	})
+}
+
+func TestLoaderIgnoresStaleCache(t *testing.T) {
+	previous := embed.EmbedVersion
+	embed.EmbedVersion = "v0.1.40"
+	t.Cleanup(func() { embed.EmbedVersion = previous })
+
+	configDir := t.TempDir()
+	writeProviderTree(t, configDir, testProviderYAML)
+
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "v0.1.39", want: false},
+		{version: "v0.1.40", want: true},
+		{version: "sha256:0123456789abcdef", want: true},
+	}
+
+	for _, tt := range tests {
+		if err := writeMetadata(filepath.Join(configDir, versionFile), Metadata{Version: tt.version}); err != nil {
+			t.Fatal(err)
+		}
+
+		providers, err := NewLoader(configDir).Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if _, ok := providers["mirror"]; ok != tt.want {
+			t.Errorf("cache version %s: loaded = %v, want %v", tt.version, ok, tt.want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(configDir, providersDir)); err != nil {
+		t.Errorf("expected stale cache to be left on disk: %v", err)
+	}
+}
@@ -3,13 +3,23 @@ package registry
 import "fmt"
 
 type Provider struct {
-	Name        string            `yaml:"name" mapstructure:"name"`
-	Type        ProviderType      `yaml:"type" mapstructure:"type"`
-	AuthType    AuthType          `yaml:"auth_type" mapstructure:"auth_type"`
-	APIKey      string            `yaml:"api_key" mapstructure:"api_key"`
-	BaseURL     string            `yaml:"base_url" mapstructure:"base_url"`
-	Description string            `yaml:"description" mapstructure:"description"`
-	Models      map[string]*Model `yaml:"-" mapstructure:"models"`
+	Name        string            `yaml:"name" json:"name" mapstructure:"name"`
+	Type        ProviderType      `yaml:"type" json:"type" mapstructure:"type"`
+	AuthType    AuthType          `yaml:"auth_type" json:"auth_type" mapstructure:"auth_type"`
+	APIKey      string            `yaml:"api_key" json:"api_key" mapstructure:"api_key"`
+	APISecret   string            `yaml:"api_secret" json:"api_secret" mapstructure:"api_secret"`
+	Region      string            `yaml:"region" json:"region" mapstructure:"region"`
+	BaseURL     string            `yaml:"base_url" json:"base_url" mapstructure:"base_url"`
+	Description string            `yaml:"description" json:"description" mapstructure:"description"`
+	AuthScheme  *AuthScheme       `yaml:"auth_scheme" json:"auth_scheme" mapstructure:"auth_scheme"`
+	OAuth       *OAuth            `yaml:"oauth" json:"oauth" mapstructure:"oauth"`
+	Headers     map[string]string `yaml:"headers" json:"headers" mapstructure:"headers"`
+	QueryParams map[string]string `yaml:"query_params" json:"query_params" mapstructure:"query_params"`
+	Regions     map[string]Region `yaml:"regions" json:"regions" mapstructure:"regions"`
+	// Residency tags where the provider processes data, for providers
+	// without regions and regions that set none.
+	Residency []string          `yaml:"residency" json:"residency" mapstructure:"residency"`
+	Models    map[string]*Model `yaml:"-" json:"models" mapstructure:"models"`
 }
 
 func (p *Provider) Validate() error {
@@ -20,11 +30,33 @@ func (p *Provider) Validate() error {
 	if p.AuthType == AuthTypeAPIKey && p.APIKey == "" {
 		return fmt.Errorf("provider %s: api_key is required when auth_type is api_key", p.Name)
 	}
+	// Without either, credentials come from the AWS SDK's default chain.
+	if p.AuthType == AuthTypeAWSCredentials && (p.APIKey == "") != (p.APISecret == "") {
+		return fmt.Errorf("provider %s: api_key and api_secret must be set together when auth_type is aws_credentials", p.Name)
+	}
+
+	if err := p.OAuth.validate(p.AuthType); err != nil {
+		return fmt.Errorf("provider %s: %w", p.Name, err)
+	}
+
+	if p.AuthScheme != nil {
+		if !p.AuthScheme.Type.valid() {
+			return fmt.Errorf("provider %s: unsupported auth_scheme type: %s", p.Name, p.AuthScheme.Type)
+		}
+		if p.AuthScheme.Type != AuthSchemeBearer && p.AuthScheme.Name == "" {
+			return fmt.Errorf("provider %s: auth_scheme name is required for type %s", p.Name, p.AuthScheme.Type)
+		}
+	}
 
 	for _, model := range p.Models {
 		if err := model.Validate(); err != nil {
 			return fmt.Errorf("provider %s: %w", p.Name, err)
 		}
+		for _, region := range model.Regions {
+			if _, ok := p.Regions[region]; !ok {
+				return fmt.Errorf("provider %s: model %s: undeclared region %s", p.Name, model.Name, region)
+			}
+		}
 	}
 
 	return nil
@@ -40,8 +72,16 @@ func (p *Provider) Copy() *Provider {
 		Type:        p.Type,
 		AuthType:    p.AuthType,
 		APIKey:      p.APIKey,
+		APISecret:   p.APISecret,
+		Region:      p.Region,
 		BaseURL:     p.BaseURL,
 		Description: p.Description,
+		AuthScheme:  p.AuthScheme.Copy(),
+		OAuth:       p.OAuth.Copy(),
+		Headers:     CopyMap(p.Headers),
+		QueryParams: CopyMap(p.QueryParams),
+		Regions:     copyRegions(p.Regions),
+		Residency:   CopySlice(p.Residency),
 		Models:      make(map[string]*Model),
 	}
 
@@ -58,9 +98,28 @@ func (p *Provider) Merge(override *Provider) {
 	SetIfNotZero(&p.Type, override.Type)
 	SetIfNotZero(&p.AuthType, override.AuthType)
 	SetIfNotZero(&p.APIKey, override.APIKey)
+	SetIfNotZero(&p.APISecret, override.APISecret)
+	SetIfNotZero(&p.Region, override.Region)
 	SetIfNotZero(&p.BaseURL, override.BaseURL)
 	SetIfNotZero(&p.Description, override.Description)
 
+	if override.AuthScheme != nil {
+		p.AuthScheme = override.AuthScheme.Copy()
+	}
+	if override.OAuth != nil {
+		if p.OAuth == nil {
+			p.OAuth = override.OAuth.Copy()
+		} else {
+			p.OAuth.Merge(override.OAuth)
+		}
+	}
+	p.Headers = MergeMap(p.Headers, override.Headers)
+	p.QueryParams = MergeMap(p.QueryParams, override.QueryParams)
+	p.Regions = MergeMap(p.Regions, copyRegions(override.Regions))
+	if len(override.Residency) > 0 {
+		p.Residency = CopySlice(override.Residency)
+	}
+
 	if len(override.Models) > 0 {
 		if p.Models == nil {
 			p.Models = make(map[string]*Model)
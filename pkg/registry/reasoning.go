@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ReasoningEffort string
+
+const (
+	ReasoningEffortNone    ReasoningEffort = "none"
+	ReasoningEffortMinimal ReasoningEffort = "minimal"
+	ReasoningEffortLow     ReasoningEffort = "low"
+	ReasoningEffortMedium  ReasoningEffort = "medium"
+	ReasoningEffortHigh    ReasoningEffort = "high"
+	ReasoningEffortXHigh   ReasoningEffort = "xhigh"
+	ReasoningEffortMax     ReasoningEffort = "max"
+	ReasoningEffortUltra   ReasoningEffort = "ultra"
+)
+
+const (
+	ThinkingLevelLow  = "low"
+	ThinkingLevelHigh = "high"
+
+	minThinkingBudget = 1024
+)
+
+var reasoningEffortScale = []ReasoningEffort{
+	ReasoningEffortNone,
+	ReasoningEffortMinimal,
+	ReasoningEffortLow,
+	ReasoningEffortMedium,
+	ReasoningEffortHigh,
+	ReasoningEffortXHigh,
+	ReasoningEffortMax,
+	ReasoningEffortUltra,
+}
+
+var reasoningEffortAliases = map[string]ReasoningEffort{
+	"off":        ReasoningEffortNone,
+	"disabled":   ReasoningEffortNone,
+	"med":        ReasoningEffortMedium,
+	"x-high":     ReasoningEffortXHigh,
+	"extra-high": ReasoningEffortXHigh,
+	"extra_high": ReasoningEffortXHigh,
+	"maximum":    ReasoningEffortMax,
+}
+
+// thinkingBudgetRatios gives the share of max_tokens spent on thinking for
+// models that take a token budget instead of an effort string.
+var thinkingBudgetRatios = map[ReasoningEffort]float64{
+	ReasoningEffortLow:    0.25,
+	ReasoningEffortMedium: 0.5,
+	ReasoningEffortHigh:   0.75,
+	ReasoningEffortXHigh:  0.9,
+	ReasoningEffortMax:    0.9,
+	ReasoningEffortUltra:  0.9,
+}
+
+// ReasoningSetting is a portable effort translated for one model. Exactly one
+// of Effort, ThinkingLevel and BudgetTokens is set unless Disabled is true.
+type ReasoningSetting struct {
+	Effort        string
+	ThinkingLevel string
+	BudgetTokens  int
+	Disabled      bool
+}
+
+func ParseReasoningEffort(s string) (ReasoningEffort, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	if alias, ok := reasoningEffortAliases[normalized]; ok {
+		return alias, nil
+	}
+
+	for _, effort := range reasoningEffortScale {
+		if string(effort) == normalized {
+			return effort, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown reasoning effort: %s", s)
+}
+
+func (e ReasoningEffort) rank() int {
+	for i, effort := range reasoningEffortScale {
+		if effort == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// Enabled reports whether the effort asks for reasoning at all.
+func (e ReasoningEffort) Enabled() bool {
+	return e != "" && e != ReasoningEffortNone
+}
+
+// reasoningEnabled reports whether a raw reasoning_effort value turns
+// reasoning on. Unknown values are reported by validation, not here.
+func reasoningEnabled(effort string) bool {
+	level, err := ParseReasoningEffort(effort)
+	return err == nil && level.Enabled()
+}
+
+func (m *Model) MapReasoningEffort(level ReasoningEffort) (*ReasoningSetting, error) {
+	return m.mapReasoningEffort(level, 0)
+}
+
+// mapReasoningEffort sizes thinking budgets from maxTokens, or from the
+// model's defaults when it is zero.
+func (m *Model) mapReasoningEffort(level ReasoningEffort, maxTokens int) (*ReasoningSetting, error) {
+	if level.rank() < 0 {
+		return nil, fmt.Errorf("model %s: unknown reasoning effort: %s", m.Name, level)
+	}
+
+	chatCompletion := m.APIs.ChatCompletion
+	if chatCompletion == nil {
+		return nil, fmt.Errorf("model %s: chat_completion api not configured", m.Name)
+	}
+	features := chatCompletion.Features
+
+	if !features.Reasoning && !features.Thinking {
+		if level == ReasoningEffortNone {
+			return &ReasoningSetting{Disabled: true}, nil
+		}
+		return nil, fmt.Errorf("model %s: reasoning is not supported", m.Name)
+	}
+
+	if len(features.ReasoningEfforts) > 0 {
+		return m.mapSupportedEffort(level, features.ReasoningEfforts)
+	}
+
+	if features.ThinkingLevels {
+		switch {
+		case level == ReasoningEffortNone:
+			return nil, fmt.Errorf("model %s: thinking cannot be disabled", m.Name)
+		case level.rank() <= ReasoningEffortLow.rank():
+			return &ReasoningSetting{ThinkingLevel: ThinkingLevelLow}, nil
+		default:
+			return &ReasoningSetting{ThinkingLevel: ThinkingLevelHigh}, nil
+		}
+	}
+
+	return m.thinkingBudget(level, maxTokens)
+}
+
+func (m *Model) mapSupportedEffort(level ReasoningEffort, supported []string) (*ReasoningSetting, error) {
+	for _, value := range supported {
+		if effort, err := ParseReasoningEffort(value); err == nil && effort == level {
+			return &ReasoningSetting{Effort: value}, nil
+		}
+	}
+
+	if level == ReasoningEffortNone {
+		return &ReasoningSetting{Disabled: true}, nil
+	}
+
+	return nil, fmt.Errorf("model %s: unsupported reasoning effort %s (supported: %s)",
+		m.Name, level, strings.Join(supported, ", "))
+}
+
+func (m *Model) thinkingBudget(level ReasoningEffort, maxTokens int) (*ReasoningSetting, error) {
+	if level == ReasoningEffortNone {
+		return &ReasoningSetting{Disabled: true}, nil
+	}
+
+	chatCompletion := m.APIs.ChatCompletion
+	if maxTokens <= 0 {
+		maxTokens = chatCompletion.Parameters.MaxTokens
+	}
+	if maxTokens <= 0 {
+		maxTokens = chatCompletion.Context.MaxOutput
+	}
+
+	// The budget must stay below max_tokens and cannot go under the
+	// provider minimum, so there is no valid budget for small outputs.
+	if maxTokens <= minThinkingBudget {
+		return nil, fmt.Errorf("model %s: max_tokens %d leaves no room for a thinking budget of at least %d",
+			m.Name, maxTokens, minThinkingBudget)
+	}
+
+	budget := int(float64(maxTokens) * thinkingBudgetRatios[level])
+	if budget < minThinkingBudget {
+		budget = minThinkingBudget
+	}
+
+	return &ReasoningSetting{BudgetTokens: budget}, nil
+}
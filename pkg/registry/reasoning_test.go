@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"testing"
+)
+
+func newReasoningModel(features Features) *Model {
+	return &Model{
+		Name: "test-model",
+		APIs: APIs{
+			ChatCompletion: &ChatCompletion{
+				Context: Context{
+					MaxInput:  200000,
+					MaxOutput: 64000,
+				},
+				Features: features,
+				Parameters: Parameters{
+					MaxTokens: 32000,
+				},
+			},
+		},
+	}
+}
+
+func TestParseReasoningEffort(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      ReasoningEffort
+		wantError bool
+	}{
+		{input: "low", want: ReasoningEffortLow},
+		{input: " HIGH ", want: ReasoningEffortHigh},
+		{input: "x-high", want: ReasoningEffortXHigh},
+		{input: "ultra", want: ReasoningEffortUltra},
+		{input: "off", want: ReasoningEffortNone},
+		{input: "extreme", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseReasoningEffort(tt.input)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMapReasoningEffort(t *testing.T) {
+	efforts := newReasoningModel(Features{
+		Reasoning:        true,
+		ReasoningEfforts: []string{"low", "medium", "high", "xhigh", "max", "ultra"},
+	})
+	levels := newReasoningModel(Features{Reasoning: true, Thinking: true, ThinkingLevels: true})
+	budget := newReasoningModel(Features{Reasoning: true})
+	plain := newReasoningModel(Features{})
+	small := newReasoningModel(Features{Reasoning: true})
+	small.APIs.ChatCompletion.Parameters.MaxTokens = minThinkingBudget
+
+	tests := []struct {
+		name      string
+		model     *Model
+		level     ReasoningEffort
+		want      ReasoningSetting
+		wantError bool
+	}{
+		{name: "supported effort", model: efforts, level: ReasoningEffortXHigh, want: ReasoningSetting{Effort: "xhigh"}},
+		{name: "ultra effort", model: efforts, level: ReasoningEffortUltra, want: ReasoningSetting{Effort: "ultra"}},
+		{name: "unsupported effort", model: efforts, level: ReasoningEffortMinimal, wantError: true},
+		{name: "effort none", model: efforts, level: ReasoningEffortNone, want: ReasoningSetting{Disabled: true}},
+		{name: "thinking level low", model: levels, level: ReasoningEffortMinimal, want: ReasoningSetting{ThinkingLevel: "low"}},
+		{name: "thinking level high", model: levels, level: ReasoningEffortMedium, want: ReasoningSetting{ThinkingLevel: "high"}},
+		{name: "thinking level none", model: levels, level: ReasoningEffortNone, wantError: true},
+		{name: "budget high", model: budget, level: ReasoningEffortHigh, want: ReasoningSetting{BudgetTokens: 24000}},
+		{name: "budget minimal", model: budget, level: ReasoningEffortMinimal, want: ReasoningSetting{BudgetTokens: 1024}},
+		{name: "budget max_tokens too small", model: small, level: ReasoningEffortLow, wantError: true},
+		{name: "budget none small", model: small, level: ReasoningEffortNone, want: ReasoningSetting{Disabled: true}},
+		{name: "budget none", model: budget, level: ReasoningEffortNone, want: ReasoningSetting{Disabled: true}},
+		{name: "no reasoning", model: plain, level: ReasoningEffortLow, wantError: true},
+		{name: "no reasoning none", model: plain, level: ReasoningEffortNone, want: ReasoningSetting{Disabled: true}},
+		{name: "unknown level", model: efforts, level: "extreme", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.model.MapReasoningEffort(tt.level)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error but got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, *got)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const regionPlaceholder = "{region}"
+
+// Region is a deployment of a provider in one geography.
+type Region struct {
+	// BaseURL defaults to the provider's BaseURL with any "{region}"
+	// placeholder replaced by the region name, as for Bedrock or Vertex.
+	BaseURL string `yaml:"base_url" json:"base_url" mapstructure:"base_url"`
+	// Residency tags where data is processed and stored, e.g. "eu" or "cn".
+	// It defaults to the provider's Residency.
+	Residency []string `yaml:"residency" json:"residency" mapstructure:"residency"`
+}
+
+func (r Region) Copy() Region {
+	return Region{BaseURL: r.BaseURL, Residency: CopySlice(r.Residency)}
+}
+
+func copyRegions(regions map[string]Region) map[string]Region {
+	if len(regions) == 0 {
+		return nil
+	}
+	copied := make(map[string]Region, len(regions))
+	for name, region := range regions {
+		copied[name] = region.Copy()
+	}
+	return copied
+}
+
+// InRegion reports whether the model is served in the named region of its
+// provider.
+func (m *Model) InRegion(region string) bool {
+	if m.Provider == nil {
+		return false
+	}
+	if _, ok := m.Provider.Regions[region]; !ok {
+		return false
+	}
+	return len(m.Regions) == 0 || slices.Contains(m.Regions, region)
+}
+
+// RegionsWithResidency returns the regions serving the model with residency
+// tag, sorted by name.
+func (m *Model) RegionsWithResidency(tag string) []string {
+	if m.Provider == nil {
+		return nil
+	}
+
+	var regions []string
+	for name, region := range m.Provider.Regions {
+		residency := region.Residency
+		if len(residency) == 0 {
+			residency = m.Provider.Residency
+		}
+		if slices.Contains(residency, tag) && m.InRegion(name) {
+			regions = append(regions, name)
+		}
+	}
+	slices.Sort(regions)
+	return regions
+}
+
+// HasResidency reports whether the model is served with residency tag.
+func (m *Model) HasResidency(tag string) bool {
+	if m.Provider == nil {
+		return false
+	}
+	if len(m.Provider.Regions) == 0 {
+		return slices.Contains(m.Provider.Residency, tag)
+	}
+	return len(m.RegionsWithResidency(tag)) > 0
+}
+
+// regionBaseURL returns the base URL for region, or the provider's default
+// when region is empty.
+func (p *Provider) regionBaseURL(region string) (string, error) {
+	if region == "" {
+		if strings.Contains(p.BaseURL, regionPlaceholder) {
+			return "", fmt.Errorf("provider %s: a region is required", p.Name)
+		}
+		return p.BaseURL, nil
+	}
+
+	r, ok := p.Regions[region]
+	if !ok {
+		return "", fmt.Errorf("provider %s: unknown region %s", p.Name, region)
+	}
+	if r.BaseURL != "" {
+		return r.BaseURL, nil
+	}
+	return strings.ReplaceAll(p.BaseURL, regionPlaceholder, region), nil
+}
+
+// defaultRegion returns the provider's region setting, an env: reference or
+// a literal, for providers that declare regions or template their BaseURL.
+func (p *Provider) defaultRegion() string {
+	if len(p.Regions) == 0 && !strings.Contains(p.BaseURL, regionPlaceholder) {
+		return ""
+	}
+	return resolveSetting(p.Region)
+}
+
+// Endpoint resolves the chat completion URL of a model in a region. An empty
+// region selects the provider's default BaseURL.
+func (r *Registry) Endpoint(providerName, modelName, region string) (string, error) {
+	model := r.Model(providerName, modelName)
+	if model == nil {
+		return "", fmt.Errorf("model %s/%s not found", providerName, modelName)
+	}
+	return model.Provider.chatCompletionURL(model, region)
+}
+
+func (p *Provider) chatCompletionURL(model *Model, region string) (string, error) {
+	chatCompletion := model.APIs.ChatCompletion
+	if chatCompletion == nil {
+		return "", fmt.Errorf("model %s: chat_completion api not configured", model.Name)
+	}
+	baseURL, err := p.regionBaseURL(region)
+	if err != nil {
+		return "", err
+	}
+	// regionBaseURL has checked that the provider declares the region.
+	if region != "" && len(model.Regions) > 0 && !slices.Contains(model.Regions, region) {
+		return "", fmt.Errorf("model %s/%s is not available in region %s", p.Name, model.Name, region)
+	}
+
+	return strings.TrimSuffix(baseURL, "/") + resolveEndpoint(chatCompletion, model.Name), nil
+}
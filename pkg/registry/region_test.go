@@ -0,0 +1,199 @@
+package registry
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testRegionalProviderYAML = `name: zai
+type: api
+auth_type: api_key
+api_key: ZAI_API_KEY
+base_url: https://api.z.ai/api/paas/v4
+regions:
+  global:
+    residency: [sg]
+  cn:
+    base_url: https://open.bigmodel.cn/api/paas/v4
+    residency: [cn]
+`
+
+const testTemplatedProviderYAML = `name: bedrock
+type: api
+auth_type: api_key
+api_key: AWS_ACCESS_KEY_ID
+base_url: https://bedrock-runtime.{region}.amazonaws.com
+regions:
+  us-east-1:
+    residency: [us]
+  eu-central-1:
+    residency: [eu]
+`
+
+func TestEndpoint(t *testing.T) {
+	reg, err := NewFromFS(fstest.MapFS{
+		"zai/provider.yaml":          {Data: []byte(testRegionalProviderYAML)},
+		"zai/models/glm.yaml":        testModel(t, "glm", nil),
+		"zai/models/glm-cn.yaml":     testModel(t, "glm-cn", func(m *Model) { m.Regions = []string{"cn"} }),
+		"bedrock/provider.yaml":      {Data: []byte(testTemplatedProviderYAML)},
+		"bedrock/models/claude.yaml": testModel(t, "claude", func(m *Model) { m.Regions = []string{"us-east-1"} }),
+	})
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	tests := []struct {
+		provider, model, region string
+		want                    string
+		wantErr                 bool
+	}{
+		{provider: "zai", model: "glm", want: "https://api.z.ai/api/paas/v4/chat/completions"},
+		{provider: "zai", model: "glm", region: "global", want: "https://api.z.ai/api/paas/v4/chat/completions"},
+		{provider: "zai", model: "glm", region: "cn", want: "https://open.bigmodel.cn/api/paas/v4/chat/completions"},
+		{provider: "zai", model: "glm-cn", region: "global", wantErr: true},
+		{provider: "zai", model: "glm", region: "eu", wantErr: true},
+		{provider: "bedrock", model: "claude", region: "us-east-1", want: "https://bedrock-runtime.us-east-1.amazonaws.com/chat/completions"},
+		{provider: "bedrock", model: "claude", region: "eu-central-1", wantErr: true},
+		{provider: "bedrock", model: "claude", wantErr: true},
+		{provider: "zai", model: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"/"+tt.model+"@"+tt.region, func(t *testing.T) {
+			got, err := reg.Endpoint(tt.provider, tt.model, tt.region)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Endpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Endpoint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var cnOnly []string
+	for _, model := range reg.FindModels(ModelFilter{Residency: "cn"}) {
+		cnOnly = append(cnOnly, model.Provider.Name+"/"+model.Name)
+	}
+	if strings.Join(cnOnly, ",") != "zai/glm,zai/glm-cn" {
+		t.Errorf("FindModels(cn) = %v", cnOnly)
+	}
+	if regions := reg.Model("bedrock", "claude").RegionsWithResidency("eu"); len(regions) != 0 {
+		t.Errorf("expected no eu regions for claude, got %v", regions)
+	}
+}
+
+func TestProviderValidateModelRegions(t *testing.T) {
+	_, err := NewFromFS(fstest.MapFS{
+		"zai/provider.yaml":   {Data: []byte(testRegionalProviderYAML)},
+		"zai/models/glm.yaml": testModel(t, "glm", func(m *Model) { m.Regions = []string{"eu"} }),
+	})
+	if err == nil || !strings.Contains(err.Error(), "undeclared region eu") {
+		t.Errorf("expected undeclared region error, got %v", err)
+	}
+}
+
+func TestProviderNewRequestRegion(t *testing.T) {
+	t.Setenv("TEST_REGION", "eu-central-1")
+
+	newProvider := func(region string) *Provider {
+		provider := &Provider{
+			Name:     "templated",
+			AuthType: AuthTypeAPIKey,
+			APIKey:   "sk-literal",
+			Region:   region,
+			BaseURL:  "https://runtime.{region}.example.com",
+			Regions:  map[string]Region{"us-east-1": {}, "eu-central-1": {}},
+		}
+		provider.Models = map[string]*Model{"m": {
+			Name:     "m",
+			Regions:  []string{"eu-central-1"},
+			Provider: provider,
+			APIs:     APIs{ChatCompletion: &ChatCompletion{APIFormat: APIFormatOpenAI}},
+		}}
+		return provider
+	}
+
+	tests := []struct {
+		name    string
+		region  string
+		want    string
+		wantErr bool
+	}{
+		{name: "literal region", region: "eu-central-1", want: "https://runtime.eu-central-1.example.com/chat/completions"},
+		{name: "region from environment", region: "env:TEST_REGION", want: "https://runtime.eu-central-1.example.com/chat/completions"},
+		{name: "no region", wantErr: true},
+		{name: "model not in region", region: "us-east-1", wantErr: true},
+		{name: "undeclared region", region: "ap-south-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newProvider(tt.region)
+			req, err := provider.NewRequest(context.Background(), provider.Models["m"], APIChatCompletion, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && req.URL.String() != tt.want {
+				t.Errorf("NewRequest() url = %q, want %q", req.URL, tt.want)
+			}
+		})
+	}
+
+	provider := newProvider("")
+	req, err := provider.NewRegionRequest(context.Background(), provider.Models["m"], APIChatCompletion, "eu-central-1", nil)
+	if err != nil || req.URL.Host != "runtime.eu-central-1.example.com" {
+		t.Errorf("NewRegionRequest() = %v, %v", req, err)
+	}
+}
+
+func TestProviderResidency(t *testing.T) {
+	reg, err := NewFromFS(fstest.MapFS{
+		"zai/provider.yaml":      {Data: []byte(testRegionalProviderYAML)},
+		"zai/models/glm.yaml":    testModel(t, "glm", nil),
+		"zai-cn/provider.yaml":   {Data: []byte("name: zai-cn\ntype: api\nauth_type: api_key\napi_key: ZAI_API_KEY\nbase_url: https://open.bigmodel.cn/api/paas/v4\nresidency: [cn]\n")},
+		"zai-cn/models/glm.yaml": testModel(t, "glm", nil),
+		"vertex/provider.yaml": {Data: []byte(`name: vertex
+type: api
+auth_type: api_key
+api_key: VERTEX_API_KEY
+base_url: https://{region}-aiplatform.googleapis.com
+residency: [eu]
+regions:
+  europe-west4: {}
+  us-central1:
+    residency: [us]
+`)},
+		"vertex/models/gemini.yaml": testModel(t, "gemini", nil),
+	})
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	tests := []struct {
+		residency string
+		want      string
+	}{
+		{residency: "cn", want: "zai/glm,zai-cn/glm"},
+		{residency: "eu", want: "vertex/gemini"},
+		{residency: "us", want: "vertex/gemini"},
+		{residency: "sg", want: "zai/glm"},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, model := range reg.FindModels(ModelFilter{Residency: tt.residency}) {
+			got = append(got, model.Provider.Name+"/"+model.Name)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("FindModels(%s) = %v, want %s", tt.residency, got, tt.want)
+		}
+	}
+
+	if regions := reg.Model("vertex", "gemini").RegionsWithResidency("eu"); strings.Join(regions, ",") != "europe-west4" {
+		t.Errorf("expected europe-west4 to take the provider's residency, got %v", regions)
+	}
+}
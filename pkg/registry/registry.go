@@ -1,7 +1,11 @@
 package registry
 
 import (
+	"context"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"sort"
@@ -10,6 +14,7 @@ import (
 
 const (
 	DefaultCheckInterval = 1 * time.Hour
+	DefaultUpdateTimeout = 1 * time.Minute
 	defaultDirPerm       = 0755
 	defaultFilePerm      = 0644
 )
@@ -18,42 +23,132 @@ type Options struct {
 	ConfigDir     string
 	AutoUpdate    bool
 	CheckInterval time.Duration
+	UpdateTimeout time.Duration
+	UpdateSource  UpdateSource
+	PublicKey     ed25519.PublicKey
+	PublicKeyID   string
+	UpdatePolicy  UpdatePolicy
+	KeepVersions  int
 	Providers     []*Provider
+	// OverridesDir is a providers tree merged over the cached data.
+	OverridesDir string
+	// Watch reloads once changes to the active cache version or OverridesDir
+	// have settled for WatchInterval, polling where notifications fail.
+	Watch         bool
+	WatchInterval time.Duration
+	// ReadOnly runs from embedded data, plus ConfigDir when given, without
+	// writing to disk. ConfigDir is optional and updates are disabled.
+	ReadOnly bool
+	// OnReload is called after every reload triggered by an update, a
+	// rollback or the watcher. It runs on the goroutine that reloaded.
+	OnReload func(ReloadEvent)
+	// Discovery polls the servers of custom providers for their models.
+	Discovery []*DiscoverySource
 }
 
+var ErrReadOnly = errors.New("registry is read-only")
+
 func New(opts Options) (*Registry, error) {
-	if opts.ConfigDir == "" {
+	return newRegistry(opts, nil)
+}
+
+// NewFromFS creates a read-only registry from a providers tree, or a bundle
+// containing one, instead of the embedded data.
+func NewFromFS(fsys fs.FS) (*Registry, error) {
+	if stat, err := fs.Stat(fsys, providersDir); err == nil && stat.IsDir() {
+		sub, err := fs.Sub(fsys, providersDir)
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	}
+
+	return newRegistry(Options{ReadOnly: true}, fsys)
+}
+
+func newRegistry(opts Options, base fs.FS) (*Registry, error) {
+	if opts.ConfigDir == "" && !opts.ReadOnly {
 		return nil, fmt.Errorf("ConfigDir is required")
 	}
+	if opts.ReadOnly && opts.AutoUpdate {
+		return nil, fmt.Errorf("AutoUpdate is not available in read-only mode")
+	}
 	if opts.CheckInterval == 0 {
 		opts.CheckInterval = DefaultCheckInterval
 	}
+	if opts.UpdateTimeout == 0 {
+		opts.UpdateTimeout = DefaultUpdateTimeout
+	}
+	if opts.WatchInterval == 0 {
+		opts.WatchInterval = DefaultWatchInterval
+	}
+	discovery, err := discoverySources(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := os.MkdirAll(opts.ConfigDir, defaultDirPerm); err != nil {
-		return nil, fmt.Errorf("create config dir: %w", err)
+	if !opts.ReadOnly {
+		if err := os.MkdirAll(opts.ConfigDir, defaultDirPerm); err != nil {
+			return nil, fmt.Errorf("create config dir: %w", err)
+		}
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	reg := &Registry{
 		Providers:       make(map[string]*Provider),
 		configDir:       opts.ConfigDir,
-		loader:          NewLoader(opts.ConfigDir),
+		loader:          &Loader{configDir: opts.ConfigDir, base: base, readOnly: opts.ReadOnly},
 		customProviders: opts.Providers,
-		stopChan:        make(chan struct{}),
+		updateTimeout:   opts.UpdateTimeout,
+		overridesDir:    opts.OverridesDir,
+		onReload:        opts.OnReload,
+		discovery:       discovery,
+		discovered:      make(map[string]map[string]*Model),
+		ctx:             ctx,
+		cancel:          cancel,
 	}
+	reg.loader.SetOverridesDir(opts.OverridesDir)
 
-	updater, err := NewUpdater(opts.ConfigDir)
-	if err != nil {
-		return nil, fmt.Errorf("create updater: %w", err)
+	if !opts.ReadOnly {
+		updater, err := NewUpdaterWithConfig(UpdaterConfig{
+			DestDir:       opts.ConfigDir,
+			Source:        opts.UpdateSource,
+			PublicKey:     opts.PublicKey,
+			PublicKeyID:   opts.PublicKeyID,
+			Policy:        opts.UpdatePolicy,
+			KeepVersions:  opts.KeepVersions,
+			CheckInterval: opts.CheckInterval,
+		})
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("create updater: %w", err)
+		}
+		reg.updater = updater
+	}
+
+	// Fingerprint before loading so edits made meanwhile are not missed.
+	var watched string
+	if opts.Watch {
+		watched = reg.fingerprint()
 	}
-	reg.updater = updater
 
 	if err := reg.reload(); err != nil {
+		cancel()
 		return nil, err
 	}
 
 	if opts.AutoUpdate {
+		reg.wg.Add(1)
 		go reg.autoUpdateLoop(opts.CheckInterval)
 	}
+	if opts.Watch {
+		reg.wg.Add(1)
+		go reg.watchLoop(opts.WatchInterval, watched)
+	}
+	for _, source := range discovery {
+		reg.wg.Add(1)
+		go reg.discoveryLoop(source)
+	}
 
 	return reg, nil
 }
@@ -90,8 +185,16 @@ func (r *Registry) Model(provider, modelName string) *Model {
 	return p.Models[modelName]
 }
 
+// reload loads and applies the data. Every load is strict, and loads are
+// serialized so an older one cannot replace the result of a newer one.
 func (r *Registry) reload() error {
-	newProviders, err := r.loader.Load()
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	return r.apply(r.loader.LoadStrict())
+}
+
+// apply swaps in freshly loaded providers. On error the current data is kept.
+func (r *Registry) apply(newProviders map[string]*Provider, err error) error {
 	if err != nil {
 		return err
 	}
@@ -99,14 +202,27 @@ func (r *Registry) reload() error {
 	if err := r.mergeCustomProviders(newProviders); err != nil {
 		return err
 	}
+	r.mergeDiscovered(newProviders)
+
+	info := r.loader.versionInfo()
+	info.Digest = providersDigest(newProviders)
 
 	r.mu.Lock()
 	r.Providers = newProviders
+	r.versionInfo = info
 	r.mu.Unlock()
 
 	return nil
 }
 
+func (r *Registry) notify(reason ReloadReason, err error) {
+	if r.onReload == nil {
+		return
+	}
+
+	r.onReload(ReloadEvent{Reason: reason, Version: r.Version().Current(), Err: err})
+}
+
 func (r *Registry) mergeCustomProviders(providers map[string]*Provider) error {
 	for _, customProvider := range r.customProviders {
 		existingProvider := providers[customProvider.Name]
@@ -116,7 +232,11 @@ func (r *Registry) mergeCustomProviders(providers map[string]*Provider) error {
 			existingProvider.Merge(customProvider)
 		}
 
-		if err := providers[customProvider.Name].Validate(); err != nil {
+		validate := providers[customProvider.Name].Validate
+		if existingProvider == nil {
+			validate = providers[customProvider.Name].validateCustom
+		}
+		if err := validate(); err != nil {
 			return err
 		}
 	}
@@ -125,42 +245,141 @@ func (r *Registry) mergeCustomProviders(providers map[string]*Provider) error {
 }
 
 func (r *Registry) autoUpdateLoop(interval time.Duration) {
-	if err := r.updater.Update(); err == nil {
-		_ = r.reload()
-	}
+	defer r.wg.Done()
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(r.scheduleCheck(interval))
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			if err := r.updater.Update(); err != nil {
-				slog.Error("failed to update registry", "error", err)
-				continue
-			}
-
-			if err := r.reload(); err != nil {
-				slog.Error("failed to reload registry", "error", err)
-				continue
-			}
-		case <-r.stopChan:
+		case <-timer.C:
+			r.check()
+			timer.Reset(r.scheduleCheck(interval))
+		case <-r.ctx.Done():
 			return
 		}
 	}
 }
 
+// check runs a periodic update. It defers to other processes sharing the
+// config dir, but still reloads to pick up what they installed.
+func (r *Registry) check() {
+	ctx, cancel := context.WithTimeout(r.ctx, r.updateTimeout)
+	defer cancel()
+
+	err := r.updater.Check(ctx)
+	if errors.Is(err, ErrUpdateInProgress) {
+		err = nil
+	}
+	if r.ctx.Err() != nil {
+		return
+	}
+
+	if err == nil {
+		r.mu.RLock()
+		unchanged := r.versionInfo.Cached == readMetadata(r.updater.metadataFile).Version
+		r.mu.RUnlock()
+
+		if unchanged {
+			r.recordUpdate(nil)
+			return
+		}
+		if err = r.reload(); err != nil {
+			err = fmt.Errorf("reload registry: %w", err)
+		}
+	}
+
+	r.recordUpdate(err)
+	r.notify(ReloadUpdate, err)
+	if err != nil {
+		slog.Error("failed to update registry", "error", err)
+	}
+}
+
+func (r *Registry) update(ctx context.Context) error {
+	if r.updater == nil {
+		return ErrReadOnly
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.updateTimeout)
+	defer cancel()
+
+	err := r.updater.Update(ctx)
+	if err == nil {
+		if err = r.reload(); err != nil {
+			err = fmt.Errorf("reload registry: %w", err)
+		}
+	}
+
+	r.recordUpdate(err)
+	r.notify(ReloadUpdate, err)
+	return err
+}
+
+// Close stops the auto-update loop, aborting any in-flight download, and
+// waits for it to exit.
 func (r *Registry) Close() error {
-	r.closeOnce.Do(func() {
-		close(r.stopChan)
-	})
+	r.cancel()
+	r.wg.Wait()
 	return nil
 }
 
 func (r *Registry) ForceUpdate() error {
-	if err := r.updater.Update(); err != nil {
-		return err
+	return r.ForceUpdateContext(context.Background())
+}
+
+// ForceUpdateContext downloads the latest release and reloads. It is aborted
+// when either ctx is done or the registry is closed.
+func (r *Registry) ForceUpdateContext(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(r.ctx, cancel)
+	defer stop()
+
+	return r.update(ctx)
+}
+
+// Rollback re-activates the previously installed version, pins it and
+// reloads. Call ForceUpdate after Unpin to resume updates.
+func (r *Registry) Rollback() error {
+	if r.updater == nil {
+		return ErrReadOnly
+	}
+
+	err := r.updater.Rollback()
+	if err == nil {
+		err = r.reload()
+	}
+	r.notify(ReloadRollback, err)
+	return err
+}
+
+func (r *Registry) Pin(version string) error {
+	if r.updater == nil {
+		return ErrReadOnly
+	}
+
+	err := r.updater.Pin(version)
+	if err == nil {
+		err = r.reload()
+	}
+	r.notify(ReloadRollback, err)
+	return err
+}
+
+func (r *Registry) Unpin() error {
+	if r.updater == nil {
+		return ErrReadOnly
+	}
+
+	return r.updater.Unpin()
+}
+
+func (r *Registry) Versions() ([]InstalledVersion, error) {
+	if r.updater == nil {
+		return nil, ErrReadOnly
 	}
 
-	return r.reload()
+	return r.updater.Versions()
 }
@@ -1,8 +1,12 @@
 package registry
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -278,3 +282,90 @@ func TestClose(t *testing.T) {
 		t.Errorf("Close() failed: %v", err)
 	}
 }
+
+func TestReadOnly(t *testing.T) {
+	reg, err := New(Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if reg.Provider(ProviderNameOpenAI) == nil {
+		t.Fatal("expected embedded providers")
+	}
+	if err := reg.ForceUpdate(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from ForceUpdate, got %v", err)
+	}
+	if err := reg.Rollback(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from Rollback, got %v", err)
+	}
+	if status := reg.UpdateStatus(); status.Version != "" {
+		t.Errorf("expected empty update status, got %+v", status)
+	}
+
+	if _, err := New(Options{ReadOnly: true, AutoUpdate: true}); err == nil {
+		t.Error("expected error for AutoUpdate in read-only mode")
+	}
+}
+
+func TestReadOnlyDoesNotWrite(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err := New(Options{ConfigDir: missing, ReadOnly: true}); err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("expected config dir not to be created, got %v", err)
+	}
+
+	configDir := t.TempDir()
+	writeProviderTree(t, configDir, testProviderYAML)
+	if err := writeMetadata(filepath.Join(configDir, versionFile), Metadata{Version: "v9.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadDir(configDir)
+
+	reg, err := New(Options{ConfigDir: configDir, ReadOnly: true})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if reg.Provider("mirror") == nil {
+		t.Error("expected cached provider to be loaded")
+	}
+	if after, _ := os.ReadDir(configDir); len(after) != len(before) {
+		t.Errorf("expected no new files in config dir, got %d entries, want %d", len(after), len(before))
+	}
+}
+
+func TestNewFromFS(t *testing.T) {
+	bundle := fstest.MapFS{
+		"providers/mirror/provider.yaml":            {Data: []byte(testProviderYAML)},
+		"providers/mirror/models/mirror-model.yaml": {Data: []byte(testModelYAML)},
+	}
+
+	reg, err := NewFromFS(bundle)
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if reg.Model("mirror", "mirror-model") == nil {
+		t.Fatal("expected model from bundle")
+	}
+	if reg.Provider(ProviderNameOpenAI) != nil {
+		t.Error("expected embedded providers not to be loaded")
+	}
+
+	info := reg.Version()
+	if info.Active != DataSourceBundle || info.Current() == "" {
+		t.Errorf("unexpected version info: %+v", info)
+	}
+
+	invalid := fstest.MapFS{
+		"mirror/provider.yaml": {Data: []byte("name: mirror\nauth_type: api_key\n")},
+	}
+	if _, err := NewFromFS(invalid); err == nil {
+		t.Error("expected validation error for invalid bundle")
+	}
+}
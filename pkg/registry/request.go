@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	headerAuthorization    = "Authorization"
+	headerContentType      = "Content-Type"
+	headerAnthropicKey     = "x-api-key"
+	headerAnthropicVersion = "anthropic-version"
+	headerGoogleAPIKey     = "x-goog-api-key"
+
+	contentTypeJSON         = "application/json"
+	defaultAnthropicVersion = "2023-06-01"
+	modelPlaceholder        = "{model}"
+)
+
+// envPrefix marks a credential setting that names an environment variable,
+// as in api_key: env:OPENAI_API_KEY. Other values are taken literally.
+const envPrefix = "env:"
+
+// envName returns the variable a setting refers to, if it refers to one.
+func envName(value string) (string, bool) {
+	return strings.CutPrefix(value, envPrefix)
+}
+
+type AuthScheme struct {
+	Type   AuthSchemeType `yaml:"type" json:"type" mapstructure:"type"`
+	Name   string         `yaml:"name" json:"name" mapstructure:"name"`
+	Prefix string         `yaml:"prefix" json:"prefix" mapstructure:"prefix"`
+}
+
+func (a *AuthScheme) Copy() *AuthScheme {
+	if a == nil {
+		return nil
+	}
+	copied := *a
+	return &copied
+}
+
+// ResolveAPIKey returns the key to send on the wire, read from the
+// environment when api_key is an env: reference.
+func (p *Provider) ResolveAPIKey() (string, error) {
+	if p.APIKey == "" {
+		return "", fmt.Errorf("provider %s: api_key is empty", p.Name)
+	}
+
+	name, ok := envName(p.APIKey)
+	if !ok {
+		return p.APIKey, nil
+	}
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	return "", fmt.Errorf("provider %s: environment variable %s is not set", p.Name, name)
+}
+
+// NewRequest builds an authenticated POST request for one of the model's APIs
+// with the provider's headers and query parameters applied. Providers with
+// regions are called in the one their region setting names.
+func (p *Provider) NewRequest(ctx context.Context, model *Model, api string, body io.Reader) (*http.Request, error) {
+	return p.NewRegionRequest(ctx, model, api, p.defaultRegion(), body)
+}
+
+// NewRegionRequest is NewRequest for the named region. An empty region
+// selects the provider's default BaseURL.
+func (p *Provider) NewRegionRequest(ctx context.Context, model *Model, api, region string, body io.Reader) (*http.Request, error) {
+	if model == nil {
+		return nil, fmt.Errorf("provider %s: model cannot be nil", p.Name)
+	}
+	if api != APIChatCompletion {
+		return nil, fmt.Errorf("provider %s: unsupported api: %s", p.Name, api)
+	}
+
+	endpoint, err := p.chatCompletionURL(model, region)
+	if err != nil {
+		return nil, err
+	}
+	chatCompletion := model.APIs.ChatCompletion
+
+	if p.AuthType != AuthTypeAPIKey {
+		return nil, fmt.Errorf("provider %s: auth_type %s is not supported", p.Name, p.AuthType)
+	}
+
+	apiKey, err := p.ResolveAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: invalid url: %w", p.Name, err)
+	}
+
+	query := u.Query()
+	for k, v := range p.QueryParams {
+		query.Set(k, v)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: create request: %w", p.Name, err)
+	}
+
+	if body != nil {
+		req.Header.Set(headerContentType, contentTypeJSON)
+	}
+	if chatCompletion.APIFormat == APIFormatAnthropic {
+		req.Header.Set(headerAnthropicVersion, defaultAnthropicVersion)
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+
+	scheme := p.authScheme(chatCompletion.APIFormat)
+	switch scheme.Type {
+	case AuthSchemeBearer:
+		req.Header.Set(headerAuthorization, "Bearer "+apiKey)
+	case AuthSchemeHeader:
+		req.Header.Set(scheme.Name, scheme.Prefix+apiKey)
+	case AuthSchemeQuery:
+		query.Set(scheme.Name, scheme.Prefix+apiKey)
+	default:
+		return nil, fmt.Errorf("provider %s: unsupported auth scheme: %s", p.Name, scheme.Type)
+	}
+
+	req.URL.RawQuery = query.Encode()
+
+	return req, nil
+}
+
+func (p *Provider) authScheme(format APIFormat) AuthScheme {
+	if p.AuthScheme != nil {
+		return *p.AuthScheme
+	}
+
+	switch format {
+	case APIFormatAnthropic:
+		return AuthScheme{Type: AuthSchemeHeader, Name: headerAnthropicKey}
+	case APIFormatGemini:
+		return AuthScheme{Type: AuthSchemeHeader, Name: headerGoogleAPIKey}
+	default:
+		return AuthScheme{Type: AuthSchemeBearer}
+	}
+}
+
+func resolveEndpoint(chatCompletion *ChatCompletion, modelName string) string {
+	endpoint := chatCompletion.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint(chatCompletion.APIFormat)
+	}
+
+	// Gemini addresses the model in the path: /models/{model}:generateContent.
+	if chatCompletion.APIFormat == APIFormatGemini && !strings.Contains(endpoint, modelPlaceholder) {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/" + modelPlaceholder + ":generateContent"
+	}
+
+	return strings.ReplaceAll(endpoint, modelPlaceholder, url.PathEscape(modelName))
+}
+
+func defaultEndpoint(format APIFormat) string {
+	switch format {
+	case APIFormatAnthropic:
+		return "/messages"
+	case APIFormatGemini:
+		return "/models"
+	case APIFormatCodex:
+		return "/responses"
+	default:
+		return "/chat/completions"
+	}
+}
+
+func (s AuthSchemeType) valid() bool {
+	switch s {
+	case AuthSchemeBearer, AuthSchemeHeader, AuthSchemeQuery:
+		return true
+	default:
+		return false
+	}
+}
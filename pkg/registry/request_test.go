@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type capturedRequest struct {
+	path   string
+	query  string
+	header http.Header
+	body   string
+}
+
+func newCaptureServer(t *testing.T) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured.path = r.URL.Path
+		captured.query = r.URL.RawQuery
+		captured.header = r.Header.Clone()
+		captured.body = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, captured
+}
+
+func sendRequest(t *testing.T, provider *Provider, model *Model) {
+	t.Helper()
+
+	req, err := provider.NewRequest(context.Background(), model, APIChatCompletion, strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("NewRequest() failed: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("send request: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func newRequestModel(name string, format APIFormat, endpoint string) *Model {
+	return &Model{
+		Name: name,
+		APIs: APIs{
+			ChatCompletion: &ChatCompletion{
+				APIFormat: format,
+				Endpoint:  endpoint,
+			},
+		},
+	}
+}
+
+func TestProviderNewRequestDefaults(t *testing.T) {
+	t.Setenv("TEST_REGISTRY_API_KEY", "secret")
+
+	tests := []struct {
+		name       string
+		model      *Model
+		wantPath   string
+		wantHeader map[string]string
+	}{
+		{
+			name:       "openai bearer",
+			model:      newRequestModel("gpt-4o", APIFormatOpenAI, "/chat/completions"),
+			wantPath:   "/v1/chat/completions",
+			wantHeader: map[string]string{"Authorization": "Bearer secret", "Content-Type": "application/json"},
+		},
+		{
+			name:       "anthropic api key header",
+			model:      newRequestModel("claude", APIFormatAnthropic, "/messages"),
+			wantPath:   "/v1/messages",
+			wantHeader: map[string]string{"X-Api-Key": "secret", "Anthropic-Version": defaultAnthropicVersion},
+		},
+		{
+			name:       "gemini model path",
+			model:      newRequestModel("gemini-2.5-pro", APIFormatGemini, "/models"),
+			wantPath:   "/v1/models/gemini-2.5-pro:generateContent",
+			wantHeader: map[string]string{"X-Goog-Api-Key": "secret"},
+		},
+		{
+			name:       "codex default endpoint",
+			model:      newRequestModel("gpt-5", APIFormatCodex, ""),
+			wantPath:   "/v1/responses",
+			wantHeader: map[string]string{"Authorization": "Bearer secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, captured := newCaptureServer(t)
+			provider := &Provider{
+				Name:     "test-provider",
+				AuthType: AuthTypeAPIKey,
+				APIKey:   "env:TEST_REGISTRY_API_KEY",
+				BaseURL:  server.URL + "/v1/",
+			}
+
+			sendRequest(t, provider, tt.model)
+
+			if captured.path != tt.wantPath {
+				t.Errorf("expected path %s, got %s", tt.wantPath, captured.path)
+			}
+			for k, v := range tt.wantHeader {
+				if got := captured.header.Get(k); got != v {
+					t.Errorf("expected header %s=%q, got %q", k, v, got)
+				}
+			}
+			if captured.body != `{}` {
+				t.Errorf("unexpected body: %q", captured.body)
+			}
+		})
+	}
+}
+
+func TestProviderNewRequestCustomScheme(t *testing.T) {
+	server, captured := newCaptureServer(t)
+
+	provider := &Provider{
+		Name:     "azure",
+		AuthType: AuthTypeAPIKey,
+		APIKey:   "literal-key",
+		BaseURL:  server.URL + "/openai/deployments/gpt-4o",
+		AuthScheme: &AuthScheme{
+			Type: AuthSchemeQuery,
+			Name: "key",
+		},
+		Headers:     map[string]string{"X-Custom": "1"},
+		QueryParams: map[string]string{"api-version": "2024-10-21"},
+	}
+
+	sendRequest(t, provider, newRequestModel("gpt-4o", APIFormatOpenAI, "/chat/completions"))
+
+	if captured.query != "api-version=2024-10-21&key=literal-key" {
+		t.Errorf("unexpected query: %s", captured.query)
+	}
+	if captured.header.Get("Authorization") != "" {
+		t.Errorf("expected no Authorization header, got %q", captured.header.Get("Authorization"))
+	}
+	if captured.header.Get("X-Custom") != "1" {
+		t.Errorf("expected custom header, got %v", captured.header)
+	}
+}
+
+func TestProviderNewRequestErrors(t *testing.T) {
+	model := newRequestModel("gpt-4o", APIFormatOpenAI, "/chat/completions")
+
+	tests := []struct {
+		name     string
+		provider *Provider
+		api      string
+	}{
+		{
+			name:     "unset env var",
+			provider: &Provider{Name: "p", AuthType: AuthTypeAPIKey, APIKey: "env:TEST_REGISTRY_UNSET_KEY"},
+			api:      APIChatCompletion,
+		},
+		{
+			name:     "oauth2 provider",
+			provider: &Provider{Name: "p", AuthType: AuthTypeOAuth2},
+			api:      APIChatCompletion,
+		},
+		{
+			name:     "unknown api",
+			provider: &Provider{Name: "p", AuthType: AuthTypeAPIKey, APIKey: "k"},
+			api:      "embeddings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.provider.NewRequest(context.Background(), model, tt.api, nil); err == nil {
+				t.Fatal("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestProviderValidateAuthScheme(t *testing.T) {
+	provider := &Provider{
+		Name:       "p",
+		AuthScheme: &AuthScheme{Type: AuthSchemeHeader},
+	}
+	if err := provider.Validate(); err == nil {
+		t.Fatal("expected error for header scheme without name")
+	}
+
+	provider.AuthScheme = &AuthScheme{Type: "cookie", Name: "session"}
+	if err := provider.Validate(); err == nil {
+		t.Fatal("expected error for unsupported scheme type")
+	}
+}
@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ModelFilter selects models in FindModels. Zero fields match everything.
+type ModelFilter struct {
+	Provider string
+	// Name matches models whose name contains it, case-insensitively.
+	Name      string
+	APIFormat APIFormat
+	Agent     string
+	// Features lists feature names, e.g. FeatureToolUse, that must all be
+	// supported.
+	Features   []string
+	MinContext int
+	MinOutput  int
+	// Residency keeps models served with this residency tag, in a region or
+	// by a provider without regions.
+	Residency         string
+	IncludeDeprecated bool
+}
+
+// Has reports whether the named feature is supported.
+func (f Features) Has(name string) bool {
+	switch name {
+	case FeatureToolUse:
+		return f.ToolUse
+	case FeatureThinking:
+		return f.Thinking
+	case FeatureThinkingLevels:
+		return f.ThinkingLevels
+	case FeatureReasoning:
+		return f.Reasoning
+	case FeatureStructuredOutput:
+		return f.StructuredOutput
+	case FeatureAudioInput:
+		return f.AudioInput
+	case FeatureImageOutput:
+		return f.ImageOutput
+	case FeatureImageInput:
+		return f.ImageInput
+	}
+	return false
+}
+
+// Match reports whether m passes every condition in the filter.
+func (f ModelFilter) Match(m *Model) bool {
+	if m == nil || (m.IsDeprecated && !f.IncludeDeprecated) {
+		return false
+	}
+	if f.Provider != "" && (m.Provider == nil || m.Provider.Name != f.Provider) {
+		return false
+	}
+	if f.Name != "" && !strings.Contains(strings.ToLower(m.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.Agent != "" && !slices.Contains(m.Agents, f.Agent) {
+		return false
+	}
+	if f.Residency != "" && !m.HasResidency(f.Residency) {
+		return false
+	}
+
+	chat := m.APIs.ChatCompletion
+	if f.APIFormat == "" && len(f.Features) == 0 && f.MinContext == 0 && f.MinOutput == 0 {
+		return true
+	}
+	if chat == nil {
+		return false
+	}
+	if f.APIFormat != "" && chat.APIFormat != f.APIFormat {
+		return false
+	}
+	if chat.Context.MaxInput < f.MinContext || chat.Context.MaxOutput < f.MinOutput {
+		return false
+	}
+	for _, feature := range f.Features {
+		if !chat.Features.Has(feature) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ModelFamily returns every provider's instance of the model family id,
+// including deprecated ones, sorted by provider.
+func (r *Registry) ModelFamily(id string) []*Model {
+	id = CanonicalModelID(id)
+
+	r.mu.RLock()
+	var models []*Model
+	for _, provider := range r.Providers {
+		for _, model := range provider.Models {
+			if model.FamilyID() == id {
+				models = append(models, model)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	sortModels(models)
+	return models
+}
+
+// FindModels returns the models matching filter, sorted by provider and name.
+func (r *Registry) FindModels(filter ModelFilter) []*Model {
+	r.mu.RLock()
+	var models []*Model
+	for _, provider := range r.Providers {
+		for _, model := range provider.Models {
+			if filter.Match(model) {
+				models = append(models, model)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	sortModels(models)
+	return models
+}
+
+func sortModels(models []*Model) {
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].Provider.Name != models[j].Provider.Name {
+			return models[i].Provider.Name < models[j].Provider.Name
+		}
+		return models[i].Name < models[j].Name
+	})
+}
+
+var (
+	// modelVendorPrefix matches "anthropic/" and Bedrock's "anthropic." or
+	// "us.anthropic." in front of a model name.
+	modelVendorPrefix = regexp.MustCompile(`^(?:[^/]+/|(?:[a-z]+\.)+)`)
+	// modelVersionSuffix matches Bedrock's "-v1:0" and OpenRouter's ":free".
+	modelVersionSuffix = regexp.MustCompile(`(?:-v\d+:\d+|:[a-z]+)$`)
+)
+
+// CanonicalModelID returns name without the vendor prefix and version or
+// variant suffix that providers add to it.
+func CanonicalModelID(name string) string {
+	id := modelVendorPrefix.ReplaceAllString(name, "")
+	if trimmed := modelVersionSuffix.ReplaceAllString(id, ""); strings.Contains(trimmed, "-") {
+		id = trimmed
+	}
+	return id
+}
@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFindModels(t *testing.T) {
+	reg, err := NewFromFS(fstest.MapFS{
+		"mirror/provider.yaml":              {Data: []byte(testProviderYAML)},
+		"mirror/models/mirror-model.yaml":   {Data: []byte(testModelYAML)},
+		"mirror/models/mirror-tools.yaml":   testModel(t, "mirror-tools", func(m *Model) { m.Agents = []string{"coder"} }),
+		"mirror/models/mirror-retired.yaml": testModel(t, "mirror-retired", func(m *Model) { m.IsDeprecated = true }),
+	})
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	tests := []struct {
+		name   string
+		filter ModelFilter
+		want   []string
+	}{
+		{name: "all", want: []string{"mirror-model", "mirror-tools"}},
+		{name: "deprecated", filter: ModelFilter{IncludeDeprecated: true}, want: []string{"mirror-model", "mirror-retired", "mirror-tools"}},
+		{name: "name", filter: ModelFilter{Name: "TOOLS"}, want: []string{"mirror-tools"}},
+		{name: "agent", filter: ModelFilter{Agent: "coder"}, want: []string{"mirror-tools"}},
+		{name: "provider", filter: ModelFilter{Provider: "other"}},
+		{name: "format", filter: ModelFilter{APIFormat: APIFormatAnthropic}},
+		{name: "context", filter: ModelFilter{MinContext: 1000}, want: []string{"mirror-model", "mirror-tools"}},
+		{name: "context too large", filter: ModelFilter{MinContext: 1001}},
+		{name: "feature", filter: ModelFilter{Features: []string{FeatureToolUse}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, model := range reg.FindModels(tt.filter) {
+				got = append(got, model.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindModels() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("FindModels() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestModelFamily(t *testing.T) {
+	provider := func(name string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(strings.ReplaceAll(testProviderYAML, "mirror", name))}
+	}
+
+	reg, err := NewFromFS(fstest.MapFS{
+		"anthropic/provider.yaml":          provider("anthropic"),
+		"anthropic/models/claude-x.yaml":   testModel(t, "claude-x", nil),
+		"anthropic-sub/provider.yaml":      provider("anthropic-sub"),
+		"anthropic-sub/models/claude.yaml": testModel(t, "claude-x", nil),
+		"bedrock/provider.yaml":            provider("bedrock"),
+		"bedrock/models/claude.yaml": testModel(t, "anthropic.claude-x-v1:0", func(m *Model) {
+			m.Family = "claude-x"
+			m.IsDeprecated = true
+		}),
+		"bedrock/models/other.yaml": testModel(t, "amazon.nova", nil),
+	})
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	defer reg.Close()
+
+	var got []string
+	for _, model := range reg.ModelFamily("claude-x") {
+		got = append(got, model.Provider.Name+"/"+model.Name)
+	}
+	want := []string{"anthropic/claude-x", "anthropic-sub/claude-x", "bedrock/anthropic.claude-x-v1:0"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ModelFamily() = %v, want %v", got, want)
+	}
+
+	if family := reg.ModelFamily("missing"); len(family) != 0 {
+		t.Errorf("expected empty family, got %v", family)
+	}
+}
+
+func TestCanonicalModelID(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "claude-3-7-sonnet-20250219", want: "claude-3-7-sonnet-20250219"},
+		{name: "anthropic.claude-3-7-sonnet-20250219-v1:0", want: "claude-3-7-sonnet-20250219"},
+		{name: "us.anthropic.claude-3-5-haiku-20241022-v1:0", want: "claude-3-5-haiku-20241022"},
+		{name: "claude-sonnet-4-5-20250929-v1:0", want: "claude-sonnet-4-5-20250929"},
+		{name: "anthropic/claude-3-opus", want: "claude-3-opus"},
+		{name: "meta-llama/llama-3.3-70b-instruct:free", want: "llama-3.3-70b-instruct"},
+		{name: "meta.llama3-8b-instruct-v1:0", want: "llama3-8b-instruct"},
+		{name: "anthropic.claude-v2:1", want: "claude-v2:1"},
+		{name: "amazon.titan-text-express-v1", want: "titan-text-express-v1"},
+		{name: "gpt-4.1", want: "gpt-4.1"},
+		{name: "gemini-2.5-pro", want: "gemini-2.5-pro"},
+	}
+
+	for _, tt := range tests {
+		if got := CanonicalModelID(tt.name); got != tt.want {
+			t.Errorf("CanonicalModelID(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestModelFamilyEmbedded(t *testing.T) {
+	reg, err := New(Options{ConfigDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	for _, id := range []string{"claude-3-7-sonnet-20250219", "anthropic.claude-3-7-sonnet-20250219-v1:0"} {
+		var got []string
+		for _, model := range reg.ModelFamily(id) {
+			got = append(got, model.Provider.Name+"/"+model.Name)
+		}
+		if !slices.Contains(got, "anthropic/claude-3-7-sonnet-20250219") || !slices.Contains(got, "bedrock/anthropic.claude-3-7-sonnet-20250219-v1:0") {
+			t.Errorf("ModelFamily(%q) = %v, want the anthropic and bedrock instances", id, got)
+		}
+	}
+}
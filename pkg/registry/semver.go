@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed "vMAJOR.MINOR.PATCH[-PRE][+BUILD]" version. Missing
+// minor and patch components default to zero.
+type semver struct {
+	major, minor, patch int
+	pre                 string
+}
+
+func parseSemver(s string) (semver, bool) {
+	var v semver
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, v.pre, _ = strings.Cut(s, "-")
+	if s == "" {
+		return v, false
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+
+	nums := []*int{&v.major, &v.minor, &v.patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		*nums[i] = n
+	}
+
+	return v, true
+}
+
+func (v semver) compare(o semver) int {
+	for _, d := range []int{v.major - o.major, v.minor - o.minor, v.patch - o.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	switch {
+	case v.pre == o.pre:
+		return 0
+	case v.pre == "":
+		return 1
+	case o.pre == "":
+		return -1
+	}
+
+	return comparePrerelease(v.pre, o.pre)
+}
+
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// versionConstraint is a set of comparisons that must all hold, parsed from
+// expressions such as "~0.1", "^1.2", ">=0.1.30 <0.2" or "0.1.x".
+type versionConstraint []versionComparison
+
+type versionComparison struct {
+	op      string
+	version semver
+}
+
+func parseConstraint(s string) (versionConstraint, error) {
+	var constraint versionConstraint
+
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' }) {
+		split := strings.IndexFunc(field, func(r rune) bool { return !strings.ContainsRune("<>=~^", r) })
+		if split < 0 {
+			return nil, fmt.Errorf("invalid version constraint %q", s)
+		}
+		op, raw := field[:split], field[split:]
+
+		bounds, err := constraintBounds(op, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		constraint = append(constraint, bounds...)
+	}
+
+	if len(constraint) == 0 {
+		return nil, fmt.Errorf("invalid version constraint %q", s)
+	}
+
+	return constraint, nil
+}
+
+func constraintBounds(op, raw string) ([]versionComparison, error) {
+	wildcard := strings.HasSuffix(raw, ".x") || strings.HasSuffix(raw, ".*")
+	raw = strings.TrimSuffix(strings.TrimSuffix(raw, ".x"), ".*")
+
+	v, ok := parseSemver(raw)
+	if !ok {
+		return nil, fmt.Errorf("invalid version %q", raw)
+	}
+	components := strings.Count(strings.TrimPrefix(raw, "v"), ".") + 1
+
+	if wildcard {
+		if op != "" && op != "=" {
+			return nil, fmt.Errorf("wildcard cannot be combined with %q", op)
+		}
+		op = "~"
+	}
+
+	switch op {
+	case "", "=":
+		if components == 3 {
+			return []versionComparison{{op: "=", version: v}}, nil
+		}
+		op = "~"
+	case ">", ">=", "<", "<=":
+		return []versionComparison{{op: op, version: v}}, nil
+	case "~", "^":
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", op)
+	}
+
+	var upper semver
+	switch {
+	case components == 1:
+		upper = semver{major: v.major + 1}
+	case op == "~":
+		upper = semver{major: v.major, minor: v.minor + 1}
+	case v.major > 0:
+		upper = semver{major: v.major + 1}
+	case v.minor > 0 || components == 2:
+		upper = semver{minor: v.minor + 1}
+	default:
+		upper = semver{patch: v.patch + 1}
+	}
+	// A bare upper bound would admit 0.2.0-rc1 for ~0.1.
+	upper.pre = "0"
+
+	return []versionComparison{{op: ">=", version: v}, {op: "<", version: upper}}, nil
+}
+
+func (c versionConstraint) matches(v semver) bool {
+	for _, cmp := range c {
+		r := v.compare(cmp.version)
+		var ok bool
+		switch cmp.op {
+		case "=":
+			ok = r == 0
+		case ">":
+			ok = r > 0
+		case ">=":
+			ok = r >= 0
+		case "<":
+			ok = r < 0
+		case "<=":
+			ok = r <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package registry
+
+import "testing"
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "v0.1.40", b: "0.1.40", want: 0},
+		{a: "v0.1.40", b: "v0.1.9", want: 1},
+		{a: "v1", b: "v0.9.9", want: 1},
+		{a: "v0.2.0-rc.1", b: "v0.2.0", want: -1},
+		{a: "v0.2.0-rc.2", b: "v0.2.0-rc.10", want: -1},
+		{a: "v0.2.0-beta", b: "v0.2.0-alpha", want: 1},
+		{a: "v0.2.0+build.1", b: "v0.2.0", want: 0},
+	}
+
+	for _, tt := range tests {
+		a, okA := parseSemver(tt.a)
+		b, okB := parseSemver(tt.b)
+		if !okA || !okB {
+			t.Fatalf("failed to parse %q or %q", tt.a, tt.b)
+		}
+		if got := a.compare(b); got != tt.want {
+			t.Errorf("compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"", "latest", "sha256:abc", "v1.2.3.4", "v1.x"} {
+		if _, ok := parseSemver(invalid); ok {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{constraint: "~0.1", match: []string{"v0.1.0", "v0.1.99"}, noMatch: []string{"v0.2.0", "v0.2.0-rc.1", "v0.0.9"}},
+		{constraint: "~1.2.3", match: []string{"v1.2.3", "v1.2.9"}, noMatch: []string{"v1.3.0", "v1.2.2"}},
+		{constraint: "^1.2", match: []string{"v1.2.0", "v1.9.0"}, noMatch: []string{"v2.0.0", "v1.1.0"}},
+		{constraint: "^0.1.5", match: []string{"v0.1.5", "v0.1.9"}, noMatch: []string{"v0.2.0"}},
+		{constraint: "^0.0.3", match: []string{"v0.0.3"}, noMatch: []string{"v0.0.4"}},
+		{constraint: "0.1.x", match: []string{"v0.1.7"}, noMatch: []string{"v0.2.0"}},
+		{constraint: "0.1.40", match: []string{"v0.1.40"}, noMatch: []string{"v0.1.41"}},
+		{constraint: ">=0.1.30, <0.2", match: []string{"v0.1.30", "v0.1.50"}, noMatch: []string{"v0.1.29", "v0.2.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := parseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("parseConstraint() failed: %v", err)
+			}
+			for _, version := range tt.match {
+				if v, _ := parseSemver(version); !c.matches(v) {
+					t.Errorf("expected %s to match", version)
+				}
+			}
+			for _, version := range tt.noMatch {
+				if v, _ := parseSemver(version); c.matches(v) {
+					t.Errorf("expected %s not to match", version)
+				}
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "~", "!1.0", ">=1.x", "~latest"} {
+		if _, err := parseConstraint(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
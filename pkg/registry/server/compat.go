@@ -0,0 +1,163 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/workpi-ai/model-registry-go/pkg/registry"
+)
+
+const (
+	anthropicVersionHeader = "anthropic-version"
+	defaultAnthropicLimit  = 20
+	maxAnthropicLimit      = 1000
+)
+
+// OpenAIModel is an entry of the OpenAI list-models response. Context and
+// capability fields are extensions that OpenAI itself does not return.
+type OpenAIModel struct {
+	ID              string             `json:"id"`
+	Object          string             `json:"object"`
+	Created         int64              `json:"created"`
+	OwnedBy         string             `json:"owned_by"`
+	APIFormat       registry.APIFormat `json:"api_format,omitempty"`
+	ContextWindow   int                `json:"context_window,omitempty"`
+	MaxOutputTokens int                `json:"max_output_tokens,omitempty"`
+	Capabilities    *registry.Features `json:"capabilities,omitempty"`
+}
+
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
+}
+
+// AnthropicModel is an entry of the Anthropic list-models response. The
+// registry does not know release dates, so created_at is left out.
+type AnthropicModel struct {
+	Type            string `json:"type"`
+	ID              string `json:"id"`
+	DisplayName     string `json:"display_name"`
+	MaxInputTokens  int    `json:"max_input_tokens,omitempty"`
+	MaxOutputTokens int    `json:"max_output_tokens,omitempty"`
+}
+
+type AnthropicModelList struct {
+	Data    []AnthropicModel `json:"data"`
+	HasMore bool             `json:"has_more"`
+	FirstID *string          `json:"first_id"`
+	LastID  *string          `json:"last_id"`
+}
+
+// ModelID is the id under which a model is listed in /v1/models.
+func ModelID(model *registry.Model) string {
+	return model.Provider.Name + "/" + model.Name
+}
+
+// serveCompat answers the /v1/models routes in the OpenAI shape, or the
+// Anthropic one for an anthropic-version header.
+func (s *Server) serveCompat(w http.ResponseWriter, r *http.Request, parts []string) {
+	w.Header().Set("Vary", anthropicVersionHeader)
+	anthropic := r.Header.Get(anthropicVersionHeader) != ""
+	filter := registry.ModelFilter{Provider: r.URL.Query().Get("provider")}
+
+	if anthropic && len(parts) == 2 {
+		limit, err := parseLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.serveData(w, r, func(snapshot *registry.Snapshot) (any, bool) {
+			return anthropicModelList(findModels(snapshot, filter), r.URL.Query().Get("after_id"), limit), true
+		})
+		return
+	}
+
+	s.serveData(w, r, func(snapshot *registry.Snapshot) (any, bool) {
+		if len(parts) == 2 {
+			list := OpenAIModelList{Object: "list", Data: []OpenAIModel{}}
+			for _, view := range findModels(snapshot, filter) {
+				list.Data = append(list.Data, openAIModel(view.Model))
+			}
+			return list, true
+		}
+
+		provider := findProvider(snapshot, parts[2])
+		if provider == nil || provider.Models[strings.Join(parts[3:], "/")] == nil {
+			return nil, false
+		}
+		model := provider.Models[strings.Join(parts[3:], "/")]
+		if anthropic {
+			return anthropicModel(model), true
+		}
+		return openAIModel(model), true
+	})
+}
+
+func openAIModel(model *registry.Model) OpenAIModel {
+	entry := OpenAIModel{
+		ID:      ModelID(model),
+		Object:  "model",
+		OwnedBy: model.Provider.Name,
+	}
+	if chat := model.APIs.ChatCompletion; chat != nil {
+		features := chat.Features
+		entry.APIFormat = chat.APIFormat
+		entry.ContextWindow = chat.Context.MaxInput
+		entry.MaxOutputTokens = chat.Context.MaxOutput
+		entry.Capabilities = &features
+	}
+	return entry
+}
+
+func anthropicModel(model *registry.Model) AnthropicModel {
+	entry := AnthropicModel{
+		Type:        "model",
+		ID:          ModelID(model),
+		DisplayName: model.Name,
+	}
+	if chat := model.APIs.ChatCompletion; chat != nil {
+		entry.MaxInputTokens = chat.Context.MaxInput
+		entry.MaxOutputTokens = chat.Context.MaxOutput
+	}
+	return entry
+}
+
+// anthropicModelList returns up to limit models following afterID.
+func anthropicModelList(views []ModelView, afterID string, limit int) AnthropicModelList {
+	start := 0
+	if afterID != "" {
+		start = len(views)
+		for i, view := range views {
+			if ModelID(view.Model) == afterID {
+				start = i + 1
+				break
+			}
+		}
+	}
+	views = views[start:]
+
+	list := AnthropicModelList{Data: []AnthropicModel{}, HasMore: len(views) > limit}
+	if list.HasMore {
+		views = views[:limit]
+	}
+	for _, view := range views {
+		list.Data = append(list.Data, anthropicModel(view.Model))
+	}
+	if len(list.Data) > 0 {
+		list.FirstID = &list.Data[0].ID
+		list.LastID = &list.Data[len(list.Data)-1].ID
+	}
+	return list
+}
+
+func parseLimit(raw string) (int, error) {
+	if raw == "" {
+		return defaultAnthropicLimit, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > maxAnthropicLimit {
+		return 0, errInvalidParam("limit", raw)
+	}
+	return n, nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIModels(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := get(t, server.URL+"/v1/models", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+
+	var list OpenAIModelList
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if list.Object != "list" || len(list.Data) != 3 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	first := list.Data[0]
+	if first.ID != "acme/acme-large" || first.Object != "model" || first.OwnedBy != "acme" {
+		t.Errorf("unexpected model: %+v", first)
+	}
+	if first.ContextWindow != 200000 || first.MaxOutputTokens != 1000 || first.Capabilities == nil || !first.Capabilities.ToolUse {
+		t.Errorf("missing extended fields: %+v", first)
+	}
+
+	resp, body = get(t, server.URL+"/v1/models/beta/beta-1", nil)
+	var model OpenAIModel
+	if err := json.Unmarshal([]byte(body), &model); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("retrieve: status %d, err %v", resp.StatusCode, err)
+	}
+	if model.ID != "beta/beta-1" {
+		t.Errorf("unexpected model id %q", model.ID)
+	}
+
+	if resp, _ := get(t, server.URL+"/v1/models/beta/missing", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestAnthropicModels(t *testing.T) {
+	server := newTestServer(t)
+	header := http.Header{"Anthropic-Version": {"2023-06-01"}}
+
+	tests := []struct {
+		query   string
+		want    []string
+		hasMore bool
+	}{
+		{query: "", want: []string{"acme/acme-large", "acme/acme-small", "beta/beta-1"}},
+		{query: "?limit=2", want: []string{"acme/acme-large", "acme/acme-small"}, hasMore: true},
+		{query: "?limit=2&after_id=acme/acme-small", want: []string{"beta/beta-1"}},
+		{query: "?provider=beta", want: []string{"beta/beta-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, body := get(t, server.URL+"/v1/models"+tt.query, header)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d: %s", resp.StatusCode, body)
+			}
+
+			var list AnthropicModelList
+			if err := json.Unmarshal([]byte(body), &list); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if list.HasMore != tt.hasMore || len(list.Data) != len(tt.want) {
+				t.Fatalf("unexpected list: %s", body)
+			}
+			for i, model := range list.Data {
+				if model.ID != tt.want[i] || model.Type != "model" {
+					t.Errorf("data[%d] = %+v, want id %s", i, model, tt.want[i])
+				}
+			}
+			if *list.FirstID != tt.want[0] || *list.LastID != tt.want[len(tt.want)-1] {
+				t.Errorf("first_id/last_id = %s/%s", *list.FirstID, *list.LastID)
+			}
+		})
+	}
+
+	if resp, _ := get(t, server.URL+"/v1/models?limit=0", header); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 for invalid limit", resp.StatusCode)
+	}
+}
+
+func TestCompatModelIDsWithSlash(t *testing.T) {
+	server := newRouterServer(t)
+
+	tests := []struct {
+		path   string
+		header http.Header
+	}{
+		{path: "/v1/models/router/openai/gpt-4o"},
+		{path: "/v1/models/router/openai%2Fgpt-4o"},
+		{path: "/v1/models/router/openai/gpt-4o", header: http.Header{"Anthropic-Version": {"2023-06-01"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := get(t, server.URL+tt.path, tt.header)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d: %s", resp.StatusCode, body)
+			}
+			if !strings.Contains(body, `"id":"router/openai/gpt-4o"`) {
+				t.Errorf("unexpected body: %s", body)
+			}
+			if strings.Contains(body, "created_at") {
+				t.Errorf("expected no made-up created_at: %s", body)
+			}
+		})
+	}
+
+	if resp, _ := get(t, server.URL+"/v1/models/router", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 without a model name", resp.StatusCode)
+	}
+}
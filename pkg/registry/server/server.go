@@ -0,0 +1,263 @@
+// Package server exposes a Registry over HTTP as read-only JSON.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/workpi-ai/model-registry-go/pkg/registry"
+)
+
+// Server serves these routes:
+//
+//	GET /providers
+//	GET /providers/{name}
+//	GET /models
+//	GET /models/{provider}/{model...}
+//	GET /version
+//	GET /healthz
+//	GET /v1/models
+//	GET /v1/models/{provider}/{model...}
+//
+// /models filters on provider, q, format, agent, feature, min_context,
+// residency and deprecated. Model names may contain slashes.
+//
+// The /v1/models routes emulate the OpenAI list-models API, or the Anthropic
+// one for clients sending an anthropic-version header.
+//
+// Data responses carry an ETag; literal credentials are redacted.
+type Server struct {
+	registry *registry.Registry
+}
+
+// ProviderSummary is a provider in the /providers listing, with model names
+// in place of the models themselves.
+type ProviderSummary struct {
+	*registry.Provider
+	Models []string `json:"models"`
+}
+
+// ModelView is a model together with the name of its provider.
+type ModelView struct {
+	Provider string `json:"provider"`
+	*registry.Model
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type healthResponse struct {
+	Status  string                `json:"status"`
+	Version string                `json:"version"`
+	Update  registry.UpdateStatus `json:"update"`
+}
+
+func New(reg *registry.Registry) *Server {
+	return &Server{registry: reg}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "healthz":
+		s.health(w)
+	case len(parts) == 1 && parts[0] == "version":
+		s.serveVersion(w, r)
+	case parts[0] == "providers" && len(parts) <= 2:
+		s.serveData(w, r, func(snapshot *registry.Snapshot) (any, bool) {
+			if len(parts) == 1 {
+				return providerSummaries(snapshot), true
+			}
+			provider := findProvider(snapshot, parts[1])
+			return provider, provider != nil
+		})
+	case parts[0] == "models" && len(parts) != 2:
+		if len(parts) == 1 {
+			filter, err := parseFilter(r)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.serveData(w, r, func(snapshot *registry.Snapshot) (any, bool) {
+				return findModels(snapshot, filter), true
+			})
+			return
+		}
+		name := strings.Join(parts[2:], "/")
+		s.serveData(w, r, func(snapshot *registry.Snapshot) (any, bool) {
+			provider := findProvider(snapshot, parts[1])
+			if provider == nil || provider.Models[name] == nil {
+				return nil, false
+			}
+			return ModelView{Provider: provider.Name, Model: provider.Models[name]}, true
+		})
+	case parts[0] == "v1" && len(parts) >= 2 && parts[1] == "models" && len(parts) != 3:
+		s.serveCompat(w, r, parts)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// serveData answers with the value build returns for the current snapshot,
+// or 304 when the client already has this data.
+func (s *Server) serveData(w http.ResponseWriter, r *http.Request, build func(*registry.Snapshot) (any, bool)) {
+	snapshot := s.registry.Snapshot()
+	v, ok := build(snapshot)
+	writeTagged(w, r, entityTag(snapshot.Version.Digest), v, ok)
+}
+
+// serveVersion is serveData for /version, tagged by all of its fields.
+func (s *Server) serveVersion(w http.ResponseWriter, r *http.Request) {
+	version := s.registry.Version()
+	b, err := json.Marshal(version)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sum := sha256.Sum256(b)
+	writeTagged(w, r, entityTag(hex.EncodeToString(sum[:8])), version, true)
+}
+
+func writeTagged(w http.ResponseWriter, r *http.Request, etag string, v any, ok bool) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", etag)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if strings.TrimSpace(ifNoneMatch) == "*" || matchesETag(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) health(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, healthResponse{
+		Status:  "ok",
+		Version: s.registry.Version().Current(),
+		Update:  s.registry.UpdateStatus(),
+	})
+}
+
+func providerSummaries(snapshot *registry.Snapshot) []ProviderSummary {
+	summaries := make([]ProviderSummary, 0, len(snapshot.Providers))
+	for _, provider := range snapshot.Providers {
+		names := make([]string, 0, len(provider.Models))
+		for name := range provider.Models {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		summaries = append(summaries, ProviderSummary{Provider: provider, Models: names})
+	}
+	return summaries
+}
+
+func findProvider(snapshot *registry.Snapshot, name string) *registry.Provider {
+	for _, provider := range snapshot.Providers {
+		if provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+func findModels(snapshot *registry.Snapshot, filter registry.ModelFilter) []ModelView {
+	views := []ModelView{}
+	for _, provider := range snapshot.Providers {
+		names := make([]string, 0, len(provider.Models))
+		for name, model := range provider.Models {
+			if filter.Match(model) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			views = append(views, ModelView{Provider: provider.Name, Model: provider.Models[name]})
+		}
+	}
+	return views
+}
+
+func parseFilter(r *http.Request) (registry.ModelFilter, error) {
+	query := r.URL.Query()
+	filter := registry.ModelFilter{
+		Provider:  query.Get("provider"),
+		Name:      query.Get("q"),
+		APIFormat: registry.APIFormat(query.Get("format")),
+		Agent:     query.Get("agent"),
+		Residency: query.Get("residency"),
+	}
+
+	for _, features := range query["feature"] {
+		for _, feature := range strings.Split(features, ",") {
+			if feature = strings.TrimSpace(feature); feature != "" {
+				filter.Features = append(filter.Features, feature)
+			}
+		}
+	}
+
+	if raw := query.Get("min_context"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return filter, errInvalidParam("min_context", raw)
+		}
+		filter.MinContext = n
+	}
+	if raw := query.Get("deprecated"); raw != "" {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errInvalidParam("deprecated", raw)
+		}
+		filter.IncludeDeprecated = b
+	}
+
+	return filter, nil
+}
+
+func errInvalidParam(name, value string) error {
+	return fmt.Errorf("invalid %s: %s", name, value)
+}
+
+func entityTag(digest string) string {
+	return `W/"` + digest + `"`
+}
+
+// matchesETag reports whether header lists etag. A "*" is handled by the
+// caller, since it only matches resources that exist.
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
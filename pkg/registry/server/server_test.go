@@ -0,0 +1,281 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/workpi-ai/model-registry-go/pkg/registry"
+)
+
+const acmeModelYAML = `name: %s
+is_deprecated: %t
+agents: [coder]
+apis:
+  chat_completion:
+    api_format: openai
+    context:
+      max_input: %d
+      max_output: 1000
+    features:
+      tool_use: %t
+    parameters:
+      max_tokens: 1000
+`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	model := func(name string, deprecated bool, maxInput int, toolUse bool) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(fmt.Sprintf(acmeModelYAML, name, deprecated, maxInput, toolUse))}
+	}
+	bundle := fstest.MapFS{
+		"providers/acme/provider.yaml":          {Data: []byte("name: acme\ntype: api\nauth_type: api_key\napi_key: ACME_API_KEY\nbase_url: https://acme.example.com/v1\nheaders:\n  X-Auth-Token: sk-literal-secret\n")},
+		"providers/acme/models/acme-large.yaml": model("acme-large", false, 200000, true),
+		"providers/acme/models/acme-small.yaml": model("acme-small", false, 8000, false),
+		"providers/acme/models/acme-old.yaml":   model("acme-old", true, 200000, true),
+		"providers/beta/provider.yaml":          {Data: []byte("name: beta\ntype: api\nauth_type: api_key\napi_key: BETA_API_KEY\n")},
+		"providers/beta/models/beta-1.yaml":     model("beta-1", false, 32000, true),
+	}
+
+	return newBundleServer(t, bundle)
+}
+
+func newBundleServer(t *testing.T, bundle fstest.MapFS) *httptest.Server {
+	t.Helper()
+
+	reg, err := registry.NewFromFS(bundle)
+	if err != nil {
+		t.Fatalf("NewFromFS() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = reg.Close() })
+
+	server := httptest.NewServer(New(reg))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newRouterServer serves a provider whose model names contain slashes, as
+// OpenRouter's do.
+func newRouterServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return newBundleServer(t, fstest.MapFS{
+		"providers/router/provider.yaml":               {Data: []byte("name: router\ntype: api\nauth_type: api_key\napi_key: ROUTER_API_KEY\n")},
+		"providers/router/models/openai.gpt-4o.yaml":   {Data: []byte(fmt.Sprintf(acmeModelYAML, "openai/gpt-4o", false, 128000, true))},
+		"providers/router/models/meta.llama-free.yaml": {Data: []byte(fmt.Sprintf(acmeModelYAML, "meta/llama-3:free", false, 8000, false))},
+	})
+}
+
+func get(t *testing.T, url string, header http.Header) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestServerRoutes(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   []string
+		notBody    []string
+	}{
+		{path: "/providers", wantStatus: 200, wantBody: []string{`"name":"acme"`, `"models":["acme-large","acme-old","acme-small"]`, `"api_key":"env:BETA_API_KEY"`}, notBody: []string{"sk-literal-secret"}},
+		{path: "/providers/acme", wantStatus: 200, wantBody: []string{`"api_key":"env:ACME_API_KEY"`, `"X-Auth-Token":"REDACTED"`, `"acme-small"`}, notBody: []string{"sk-literal-secret"}},
+		{path: "/providers/missing", wantStatus: 404, wantBody: []string{`"error":"not found"`}},
+		{path: "/models/acme/acme-large", wantStatus: 200, wantBody: []string{`"provider":"acme"`, `"name":"acme-large"`}},
+		{path: "/models/acme/missing", wantStatus: 404},
+		{path: "/models", wantStatus: 200, wantBody: []string{"acme-large", "acme-small", "beta-1"}, notBody: []string{"acme-old"}},
+		{path: "/models?deprecated=true", wantStatus: 200, wantBody: []string{"acme-old"}},
+		{path: "/models?provider=beta", wantStatus: 200, wantBody: []string{"beta-1"}, notBody: []string{"acme-large"}},
+		{path: "/models?q=SMALL", wantStatus: 200, wantBody: []string{"acme-small"}, notBody: []string{"acme-large", "beta-1"}},
+		{path: "/models?feature=tool_use&min_context=100000", wantStatus: 200, wantBody: []string{"acme-large"}, notBody: []string{"acme-small", "beta-1"}},
+		{path: "/models?agent=reviewer", wantStatus: 200, wantBody: []string{"[]"}},
+		{path: "/models?min_context=lots", wantStatus: 400, wantBody: []string{"min_context"}},
+		{path: "/version", wantStatus: 200, wantBody: []string{`"active":"bundle"`}},
+		{path: "/healthz", wantStatus: 200, wantBody: []string{`"status":"ok"`}},
+		{path: "/unknown", wantStatus: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := get(t, server.URL+tt.path, nil)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %s: %s", want, body)
+				}
+			}
+			for _, unwanted := range tt.notBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %s: %s", unwanted, body)
+				}
+			}
+		})
+	}
+}
+
+func TestServerETag(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := get(t, server.URL+"/models", nil)
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	var models []ModelView
+	if err := json.Unmarshal([]byte(body), &models); err != nil {
+		t.Fatalf("decode models: %v", err)
+	}
+	if len(models) != 3 || models[0].Provider != "acme" {
+		t.Errorf("unexpected models: %+v", models)
+	}
+
+	resp, _ = get(t, server.URL+"/providers", http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("status = %d, want 304 for matching ETag", resp.StatusCode)
+	}
+
+	resp, _ = get(t, server.URL+"/providers", http.Header{"If-None-Match": {`W/"stale"`}})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 for stale ETag", resp.StatusCode)
+	}
+
+	resp, _ = get(t, server.URL+"/healthz", nil)
+	if resp.Header.Get("ETag") != "" {
+		t.Error("expected no ETag on /healthz")
+	}
+}
+
+func TestServerModelNamesWithSlash(t *testing.T) {
+	server := newRouterServer(t)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantName   string
+	}{
+		{path: "/models/router/openai/gpt-4o", wantStatus: 200, wantName: "openai/gpt-4o"},
+		{path: "/models/router/openai%2Fgpt-4o", wantStatus: 200, wantName: "openai/gpt-4o"},
+		{path: "/models/router/meta/llama-3:free", wantStatus: 200, wantName: "meta/llama-3:free"},
+		{path: "/models/router/openai", wantStatus: 404},
+		{path: "/models/router", wantStatus: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := get(t, server.URL+tt.path, nil)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantName == "" {
+				return
+			}
+			var view ModelView
+			if err := json.Unmarshal([]byte(body), &view); err != nil {
+				t.Fatalf("decode model: %v", err)
+			}
+			if view.Provider != "router" || view.Name != tt.wantName {
+				t.Errorf("got %s/%s, want router/%s", view.Provider, view.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestServerETagStableAcrossReloads(t *testing.T) {
+	first, _ := get(t, newTestServer(t).URL+"/providers", nil)
+	second, _ := get(t, newTestServer(t).URL+"/providers", nil)
+	if first.Header.Get("ETag") != second.Header.Get("ETag") {
+		t.Errorf("ETag changed for the same data: %s != %s", first.Header.Get("ETag"), second.Header.Get("ETag"))
+	}
+
+	other, _ := get(t, newRouterServer(t).URL+"/providers", nil)
+	if other.Header.Get("ETag") == first.Header.Get("ETag") {
+		t.Error("expected a different ETag for different data")
+	}
+
+	server := newTestServer(t)
+	if resp, _ := get(t, server.URL+"/providers/acme", http.Header{"If-None-Match": {"*"}}); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("status = %d, want 304 for * on an existing provider", resp.StatusCode)
+	}
+	if resp, _ := get(t, server.URL+"/providers/missing", http.Header{"If-None-Match": {"*"}}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 for * on a missing provider", resp.StatusCode)
+	}
+}
+
+func TestServerETagChecksExistence(t *testing.T) {
+	server := newTestServer(t)
+	resp, _ := get(t, server.URL+"/providers", nil)
+	etag := resp.Header.Get("ETag")
+
+	if resp, _ := get(t, server.URL+"/providers/missing", http.Header{"If-None-Match": {etag}}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 for a current ETag on a missing provider", resp.StatusCode)
+	}
+	if resp, _ := get(t, server.URL+"/models/acme/missing", http.Header{"If-None-Match": {etag}}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 for a current ETag on a missing model", resp.StatusCode)
+	}
+}
+
+func TestServerVersionETag(t *testing.T) {
+	first := newTestServer(t)
+	resp, _ := get(t, first.URL+"/version", nil)
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag on /version")
+	}
+	if resp, _ := get(t, first.URL+"/version", http.Header{"If-None-Match": {etag}}); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("status = %d, want 304 for a current /version ETag", resp.StatusCode)
+	}
+
+	// The same data loaded again has a new LoadedAt.
+	time.Sleep(time.Millisecond)
+	second := newTestServer(t)
+	if resp, _ := get(t, second.URL+"/version", http.Header{"If-None-Match": {etag}}); resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 after a reload", resp.StatusCode)
+	}
+}
+
+func TestServerRejectsWrites(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Post(server.URL+"/providers", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", resp.StatusCode)
+	}
+}
@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const redactedValue = "REDACTED"
+
+var sensitiveNameParts = []string{"key", "token", "secret", "auth", "password"}
+
+// VersionInfo identifies the registry data in effect.
+type VersionInfo struct {
+	Embedded  string     `json:"embedded"`
+	Cached    string     `json:"cached,omitempty"`
+	Bundle    string     `json:"bundle,omitempty"`
+	Active    DataSource `json:"active"`
+	Pinned    bool       `json:"pinned,omitempty"`
+	Overrides string     `json:"overrides,omitempty"`
+	// Digest hashes the loaded data as Snapshot returns it, so it survives
+	// reloads of the same data.
+	Digest   string    `json:"digest"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// Current returns the version of the active data.
+func (v VersionInfo) Current() string {
+	switch v.Active {
+	case DataSourceCache:
+		return v.Cached
+	case DataSourceBundle:
+		return v.Bundle
+	}
+	return v.Embedded
+}
+
+// Snapshot is a point-in-time copy of the registry suitable for bug reports
+// and diagnostics. Literal credentials are redacted.
+type Snapshot struct {
+	Version   VersionInfo `json:"version"`
+	Providers []*Provider `json:"providers"`
+}
+
+// Version reports which data was in effect at the last successful load.
+func (r *Registry) Version() VersionInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.versionInfo
+}
+
+func (r *Registry) Snapshot() *Snapshot {
+	r.mu.RLock()
+	snapshot := &Snapshot{
+		Version:   r.versionInfo,
+		Providers: make([]*Provider, 0, len(r.Providers)),
+	}
+	for _, provider := range r.Providers {
+		snapshot.Providers = append(snapshot.Providers, provider.redacted())
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(snapshot.Providers, func(i, j int) bool {
+		return snapshot.Providers[i].Name < snapshot.Providers[j].Name
+	})
+	return snapshot
+}
+
+func (l *Loader) versionInfo() VersionInfo {
+	var metadata Metadata
+	if l.configDir != "" {
+		metadata = readMetadata(filepath.Join(l.configDir, versionFile))
+	}
+
+	info := VersionInfo{
+		Embedded:  embeddedVersion(),
+		Cached:    metadata.Version,
+		Active:    DataSourceEmbedded,
+		Pinned:    metadata.Pinned,
+		Overrides: l.overridesDir,
+		LoadedAt:  time.Now(),
+	}
+
+	if l.base != nil {
+		info.Active = DataSourceBundle
+		info.Bundle = fsVersion(l.base)
+	}
+	if l.cacheActive(metadata) {
+		info.Active = DataSourceCache
+	}
+
+	return info
+}
+
+// redacted returns a copy safe to share. env: references are kept since they
+// are needed to reproduce a setup; literal credentials are not.
+func (p *Provider) redacted() *Provider {
+	copied := p.Copy()
+	redactLiteral(&copied.APIKey)
+	redactLiteral(&copied.APISecret)
+	redactSensitive(copied.Headers)
+	redactSensitive(copied.QueryParams)
+	return copied
+}
+
+func redactLiteral(value *string) {
+	if _, ok := envName(*value); !ok && *value != "" {
+		*value = redactedValue
+	}
+}
+
+func redactSensitive(values map[string]string) {
+	for name := range values {
+		lower := strings.ToLower(name)
+		for _, part := range sensitiveNameParts {
+			if strings.Contains(lower, part) {
+				values[name] = redactedValue
+				break
+			}
+		}
+	}
+}
+
+func providersDigest(providers map[string]*Provider) string {
+	redacted := make(map[string]*Provider, len(providers))
+	for name, provider := range providers {
+		redacted[name] = provider.redacted()
+	}
+
+	// Map keys are marshaled in sorted order, so equal data hashes equally.
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// fsVersion hashes the YAML files of a providers tree like contentVersion
+// does for downloaded bundles.
+func fsVersion(fsys fs.FS) string {
+	files := make(map[string][]byte)
+	_ = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, yamlExt) {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		files[path] = content
+		return nil
+	})
+
+	return contentVersion(files)
+}
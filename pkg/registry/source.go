@@ -0,0 +1,441 @@
+package registry
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+)
+
+const (
+	headerETag            = "ETag"
+	headerLastModified    = "Last-Modified"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+	headerRegistryVersion = "X-Registry-Version"
+
+	contentVersionPrefix = "sha256:"
+	maxBundleSize        = 64 << 20
+	maxListedReleases    = 100
+)
+
+// Bundle is a downloaded copy of the registry data, keyed by slash-separated
+// path such as "providers/openai/provider.yaml".
+type Bundle struct {
+	Version      string
+	ETag         string
+	LastModified string
+	Files        map[string][]byte
+}
+
+// UpdateSource fetches registry bundles. Fetch returns a nil bundle when the
+// data described by current is still up to date.
+type UpdateSource interface {
+	Name() string
+	Fetch(ctx context.Context, current Metadata) (*Bundle, error)
+}
+
+type GitHubSource struct {
+	Owner           string
+	Repo            string
+	Client          *github.Client
+	HTTPClient      *http.Client
+	RequestTimeout  time.Duration
+	DownloadTimeout time.Duration
+}
+
+func NewGitHubSource(owner, repo string) *GitHubSource {
+	return &GitHubSource{
+		Owner:           owner,
+		Repo:            repo,
+		Client:          github.NewClient(nil),
+		HTTPClient:      http.DefaultClient,
+		RequestTimeout:  defaultRequestTimeout,
+		DownloadTimeout: defaultDownloadTimeout,
+	}
+}
+
+func (s *GitHubSource) Name() string {
+	return "github:" + s.Owner + "/" + s.Repo
+}
+
+func (s *GitHubSource) Fetch(ctx context.Context, current Metadata) (*Bundle, error) {
+	version, zipballURL, err := s.latestRelease(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get latest version: %w", err)
+	}
+
+	if version == current.Version {
+		return nil, nil
+	}
+
+	return s.FetchRelease(ctx, Release{Version: version, URL: zipballURL})
+}
+
+func (s *GitHubSource) ListReleases(ctx context.Context) ([]Release, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.RequestTimeout)
+	defer cancel()
+
+	list, _, err := s.Client.Repositories.ListReleases(ctx, s.Owner, s.Repo, &github.ListOptions{PerPage: maxListedReleases})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	releases := make([]Release, 0, len(list))
+	for _, release := range list {
+		if release.GetDraft() || release.TagName == nil || release.ZipballURL == nil {
+			continue
+		}
+		releases = append(releases, Release{
+			Version:    release.GetTagName(),
+			Prerelease: release.GetPrerelease(),
+			URL:        release.GetZipballURL(),
+		})
+	}
+
+	return releases, nil
+}
+
+func (s *GitHubSource) FetchRelease(ctx context.Context, release Release) (*Bundle, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.DownloadTimeout)
+	defer cancel()
+
+	data, _, err := download(ctx, s.HTTPClient, release.URL, Metadata{})
+	if err != nil {
+		return nil, fmt.Errorf("download release: %w", err)
+	}
+
+	files, err := readZip(data)
+	if err != nil {
+		return nil, fmt.Errorf("read release archive: %w", err)
+	}
+
+	return &Bundle{Version: release.Version, Files: files}, nil
+}
+
+func (s *GitHubSource) latestRelease(ctx context.Context) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.RequestTimeout)
+	defer cancel()
+
+	release, _, err := s.Client.Repositories.GetLatestRelease(ctx, s.Owner, s.Repo)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get latest release: %w", err)
+	}
+
+	if release.TagName == nil {
+		return "", "", fmt.Errorf("release tag name is nil")
+	}
+	if release.ZipballURL == nil {
+		return "", "", fmt.Errorf("release zipball_url is nil")
+	}
+
+	return *release.TagName, *release.ZipballURL, nil
+}
+
+// HTTPSource downloads a zip or gzipped tarball from a URL, versioned by the
+// X-Registry-Version header or else by content.
+type HTTPSource struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{URL: url, HTTPClient: http.DefaultClient}
+}
+
+func (s *HTTPSource) Name() string {
+	return "http:" + s.URL
+}
+
+func (s *HTTPSource) Fetch(ctx context.Context, current Metadata) (*Bundle, error) {
+	data, header, err := download(ctx, s.HTTPClient, s.URL, current)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	files, err := readArchive(data)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.URL, err)
+	}
+
+	bundle := &Bundle{
+		Version:      header.Get(headerRegistryVersion),
+		ETag:         header.Get(headerETag),
+		LastModified: header.Get(headerLastModified),
+		Files:        files,
+	}
+	if bundle.Version == "" {
+		bundle.Version = contentVersion(files)
+	}
+	if bundle.Version == current.Version {
+		return nil, nil
+	}
+
+	return bundle, nil
+}
+
+// DirSource reads registry data from a local directory containing a
+// providers/ tree, or from a local zip or tarball.
+type DirSource struct {
+	Path string
+}
+
+func NewDirSource(path string) *DirSource {
+	return &DirSource{Path: path}
+}
+
+func (s *DirSource) Name() string {
+	return "dir:" + s.Path
+}
+
+func (s *DirSource) Fetch(ctx context.Context, current Metadata) (*Bundle, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string][]byte
+	if stat.IsDir() {
+		files, err = readFS(os.DirFS(s.Path))
+	} else {
+		var data []byte
+		data, err = os.ReadFile(s.Path)
+		if err == nil {
+			files, err = readArchive(data)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.Path, err)
+	}
+
+	version := contentVersion(files)
+	if version == current.Version {
+		return nil, nil
+	}
+
+	return &Bundle{Version: version, Files: files}, nil
+}
+
+// download performs a conditional GET. It returns nil data when the server
+// answers 304 Not Modified.
+func download(ctx context.Context, client *http.Client, url string, current Metadata) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if current.ETag != "" {
+		req.Header.Set(headerIfNoneMatch, current.ETag)
+	}
+	if current.LastModified != "" {
+		req.Header.Set(headerIfModifiedSince, current.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, resp.Header, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, url)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBundleSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if len(data) > maxBundleSize {
+		return nil, nil, fmt.Errorf("bundle exceeds %d bytes: %s", maxBundleSize, url)
+	}
+
+	return data, resp.Header, nil
+}
+
+func readArchive(data []byte) (map[string][]byte, error) {
+	if bytes.HasPrefix(data, []byte("PK")) {
+		return readZip(data)
+	}
+	return readTarGz(data)
+}
+
+func readZip(data []byte) (map[string][]byte, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	remaining := int64(maxBundleSize)
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		name, ok := bundlePath(file.Name)
+		if !ok {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := readBundleEntry(rc, name, &remaining)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		files[name] = content
+	}
+
+	return files, nil
+}
+
+func readTarGz(data []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	remaining := int64(maxBundleSize)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name, ok := bundlePath(header.Name)
+		if !ok {
+			continue
+		}
+
+		content, err := readBundleEntry(tr, name, &remaining)
+		if err != nil {
+			return nil, err
+		}
+
+		files[name] = content
+	}
+
+	return files, nil
+}
+
+// readBundleEntry reads one archive entry, failing once the bundle exceeds
+// its size limit.
+func readBundleEntry(r io.Reader, name string, remaining *int64) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, *remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > *remaining {
+		return nil, fmt.Errorf("bundle exceeds %d bytes when extracted: %s", maxBundleSize, name)
+	}
+	*remaining -= int64(len(content))
+	return content, nil
+}
+
+func readFS(fsys fs.FS) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		name, ok := bundlePath(p)
+		if !ok {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		files[name] = content
+		return nil
+	})
+
+	return files, err
+}
+
+// bundlePath maps an archive entry to its bundle path. Archives may wrap the
+// data in a root directory (GitHub zipballs do). Only YAML under providers/
+// and the checksum manifest with its signature are kept.
+func bundlePath(name string) (string, bool) {
+	name = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+
+	if base := path.Base(name); base == manifestFile || base == signatureFile {
+		return base, strings.Count(name, "/") <= 1
+	}
+
+	prefix := providersDir + "/"
+	idx := strings.Index(name, prefix)
+	if idx < 0 || (idx > 0 && name[idx-1] != '/') {
+		return "", false
+	}
+	if strings.Count(name[:idx], "/") > 1 {
+		return "", false
+	}
+
+	name = name[idx:]
+	if !strings.HasSuffix(name, yamlExt) {
+		return "", false
+	}
+
+	return name, true
+}
+
+// contentVersion derives a stable version from the bundle content for
+// sources that do not publish one.
+func contentVersion(files map[string][]byte) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fileHash := sha256.Sum256(files[name])
+		fmt.Fprintf(h, "%s  %s\n", hex.EncodeToString(fileHash[:]), name)
+	}
+
+	return contentVersionPrefix + hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,256 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func createTestTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatalf("write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("write tar entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestBundlePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{name: "plain", input: "providers/openai/provider.yaml", want: "providers/openai/provider.yaml", wantOK: true},
+		{name: "zipball root", input: "workpi-ai-model-registry-abc/providers/openai/provider.yaml", want: "providers/openai/provider.yaml", wantOK: true},
+		{name: "traversal", input: "root/../../providers/evil.yaml", want: "providers/evil.yaml", wantOK: true},
+		{name: "nested too deep", input: "a/b/providers/openai/provider.yaml"},
+		{name: "non yaml", input: "providers/README.md"},
+		{name: "outside providers", input: "root/docs/a.yaml"},
+		{name: "prefix collision", input: "root/myproviders/a.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := bundlePath(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("bundlePath(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceConditionalFetch(t *testing.T) {
+	archive := createTestTarGz(t, map[string]string{
+		"bundle/providers/mirror/provider.yaml":            testProviderYAML,
+		"bundle/providers/mirror/models/mirror-model.yaml": testModelYAML,
+	})
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get(headerIfNoneMatch) == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set(headerETag, `"v2"`)
+		w.Header().Set(headerRegistryVersion, "v2.0.0")
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL + "/registry.tar.gz")
+
+	bundle, err := source.Fetch(context.Background(), Metadata{})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if bundle == nil {
+		t.Fatal("expected bundle on first fetch")
+	}
+	if bundle.Version != "v2.0.0" || bundle.ETag != `"v2"` {
+		t.Errorf("unexpected bundle metadata: version=%q etag=%q", bundle.Version, bundle.ETag)
+	}
+	if len(bundle.Files) != 2 {
+		t.Errorf("expected 2 files, got %d", len(bundle.Files))
+	}
+
+	bundle, err = source.Fetch(context.Background(), Metadata{Version: "v2.0.0", ETag: `"v2"`})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if bundle != nil {
+		t.Errorf("expected nil bundle for 304, got %+v", bundle)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestHTTPSourceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if _, err := NewHTTPSource(server.URL).Fetch(context.Background(), Metadata{}); err == nil {
+		t.Fatal("expected error for HTTP 403")
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	providerDir := filepath.Join(dir, providersDir, "mirror")
+	if err := os.MkdirAll(filepath.Join(providerDir, "models"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(providerDir, providerYAML), []byte(testProviderYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(providerDir, "models", "mirror-model.yaml"), []byte(testModelYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewDirSource(dir)
+	bundle, err := source.Fetch(context.Background(), Metadata{})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if bundle == nil || len(bundle.Files) != 2 {
+		t.Fatalf("expected bundle with 2 files, got %+v", bundle)
+	}
+
+	again, err := source.Fetch(context.Background(), Metadata{Version: bundle.Version})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if again != nil {
+		t.Errorf("expected nil bundle for unchanged directory, got version %s", again.Version)
+	}
+}
+
+func TestDirSourceArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "registry.zip")
+	archive := createTestZip(t, map[string]string{
+		"providers/mirror/provider.yaml": testProviderYAML,
+	})
+	if err := os.WriteFile(archivePath, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := NewDirSource(archivePath).Fetch(context.Background(), Metadata{})
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if string(bundle.Files["providers/mirror/provider.yaml"]) != testProviderYAML {
+		t.Errorf("unexpected bundle files: %v", bundle.Files)
+	}
+}
+
+func TestRegistryWithDirSource(t *testing.T) {
+	dataDir := t.TempDir()
+	archivePath := filepath.Join(dataDir, "registry.zip")
+	archive := createTestZip(t, map[string]string{
+		"providers/mirror/provider.yaml":            testProviderYAML,
+		"providers/mirror/models/mirror-model.yaml": testModelYAML,
+	})
+	if err := os.WriteFile(archivePath, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg, err := New(Options{
+		ConfigDir:    t.TempDir(),
+		UpdateSource: NewDirSource(archivePath),
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if err := reg.ForceUpdate(); err != nil {
+		t.Fatalf("ForceUpdate() failed: %v", err)
+	}
+
+	if reg.Model("mirror", "mirror-model") == nil {
+		t.Fatal("expected model from dir source after update")
+	}
+}
+
+func TestReadBundleEntryLimit(t *testing.T) {
+	remaining := int64(10)
+	if _, err := readBundleEntry(strings.NewReader("123456"), "a.yaml", &remaining); err != nil {
+		t.Fatalf("readBundleEntry() failed: %v", err)
+	}
+	if remaining != 4 {
+		t.Errorf("expected 4 bytes remaining, got %d", remaining)
+	}
+	if _, err := readBundleEntry(strings.NewReader("12345"), "b.yaml", &remaining); err == nil {
+		t.Fatal("expected error for entry past the bundle limit")
+	}
+}
+
+func TestUpdaterIgnoresValidatorsFromOtherSource(t *testing.T) {
+	archive := createTestTarGz(t, map[string]string{
+		"providers/mirror/provider.yaml":            testProviderYAML,
+		"providers/mirror/models/mirror-model.yaml": testModelYAML,
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(headerIfNoneMatch) != "" || r.Header.Get(headerIfModifiedSince) != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set(headerRegistryVersion, "v2.0.0")
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	destDir := t.TempDir()
+	if err := writeMetadata(filepath.Join(destDir, versionFile), Metadata{
+		Version:      "v1.0.0",
+		ETag:         `"old"`,
+		LastModified: "Mon, 01 Jan 2024 00:00:00 GMT",
+		Source:       "http:https://old.example/registry.tar.gz",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: NewHTTPSource(server.URL + "/registry.tar.gz")})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+
+	metadata := readMetadata(filepath.Join(destDir, versionFile))
+	if metadata.Version != "v2.0.0" || metadata.Source != "http:"+server.URL+"/registry.tar.gz" {
+		t.Errorf("expected bundle from the new source, got %+v", metadata)
+	}
+}
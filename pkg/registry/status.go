@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"math/rand"
+	"time"
+)
+
+const minRetryDelay = 30 * time.Second
+
+// UpdateStatus describes the auto-update state for health checks, including
+// updates made by other processes.
+type UpdateStatus struct {
+	LastAttempt time.Time `json:"last_attempt"`
+	LastSuccess time.Time `json:"last_success"`
+	NextCheck   time.Time `json:"next_check"`
+	Failures    int       `json:"failures"`
+	Version     string    `json:"version"`
+	Source      string    `json:"source"`
+	Pinned      bool      `json:"pinned"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+func (r *Registry) UpdateStatus() UpdateStatus {
+	r.statusMu.Lock()
+	status := r.status
+	r.statusMu.Unlock()
+
+	if r.updater == nil {
+		return status
+	}
+
+	metadata := readMetadata(r.updater.metadataFile)
+	status.Version = metadata.Version
+	status.Source = metadata.Source
+	status.Pinned = metadata.Pinned
+	if checked := parseCheckTime(metadata.LastCheckAt); checked.After(status.LastSuccess) {
+		status.LastSuccess = checked
+	}
+
+	return status
+}
+
+func (r *Registry) recordUpdate(err error) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+
+	now := time.Now()
+	r.status.LastAttempt = now
+	if err != nil {
+		r.status.Failures++
+		r.status.LastError = err.Error()
+		return
+	}
+
+	r.status.LastSuccess = now
+	r.status.Failures = 0
+	r.status.LastError = ""
+}
+
+// scheduleCheck returns the delay until the next periodic check and records
+// it in the status.
+func (r *Registry) scheduleCheck(interval time.Duration) time.Duration {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+
+	lastCheck := parseCheckTime(readMetadata(r.updater.metadataFile).LastCheckAt)
+	delay := nextCheckDelay(lastCheck, interval, r.status.Failures)
+	r.status.NextCheck = time.Now().Add(delay)
+
+	return delay
+}
+
+// nextCheckDelay backs off after failures, capped at interval, and otherwise
+// waits for interval since the last check by any process.
+func nextCheckDelay(lastCheck time.Time, interval time.Duration, failures int) time.Duration {
+	if failures > 0 {
+		backoff := minRetryDelay
+		for i := 1; i < failures && backoff < interval; i++ {
+			backoff *= 2
+		}
+		if backoff > interval {
+			backoff = interval
+		}
+		return backoff/2 + jitter(backoff/2)
+	}
+
+	delay := time.Until(lastCheck.Add(interval))
+	if delay <= 0 {
+		return 0
+	}
+	// Spread the checks of processes that were started together.
+	return delay + jitter(interval/10)
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNextCheckDelay(t *testing.T) {
+	interval := time.Hour
+
+	tests := []struct {
+		name      string
+		lastCheck time.Time
+		failures  int
+		min, max  time.Duration
+	}{
+		{name: "never checked", min: 0, max: 0},
+		{name: "overdue", lastCheck: time.Now().Add(-2 * interval), min: 0, max: 0},
+		{name: "recent check", lastCheck: time.Now().Add(-15 * time.Minute), min: 44 * time.Minute, max: 51 * time.Minute},
+		{name: "first failure", failures: 1, min: minRetryDelay / 2, max: minRetryDelay},
+		{name: "third failure", failures: 3, min: 2 * minRetryDelay, max: 4 * minRetryDelay},
+		{name: "capped", failures: 40, min: interval / 2, max: interval},
+		{name: "capped past shift overflow", failures: 30, min: interval / 2, max: interval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got := nextCheckDelay(tt.lastCheck, interval, tt.failures)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("nextCheckDelay() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	fail := true
+	archive := createTestZip(t, map[string]string{
+		"providers/mirror/provider.yaml": testProviderYAML,
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(headerRegistryVersion, "v1.0.0")
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	reg, err := New(Options{ConfigDir: t.TempDir(), UpdateSource: NewHTTPSource(server.URL)})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if err := reg.ForceUpdate(); err == nil {
+		t.Fatal("expected update to fail")
+	}
+
+	status := reg.UpdateStatus()
+	if status.Failures != 1 || status.LastError == "" || status.LastAttempt.IsZero() {
+		t.Errorf("unexpected status after failure: %+v", status)
+	}
+	if !status.LastSuccess.IsZero() {
+		t.Errorf("expected no successful update, got %v", status.LastSuccess)
+	}
+
+	fail = false
+	if err := reg.ForceUpdate(); err != nil {
+		t.Fatalf("ForceUpdate() failed: %v", err)
+	}
+
+	status = reg.UpdateStatus()
+	if status.Failures != 0 || status.LastError != "" {
+		t.Errorf("expected failures to reset, got %+v", status)
+	}
+	if status.Version != "v1.0.0" || status.Source != "http:"+server.URL {
+		t.Errorf("unexpected version or source: %+v", status)
+	}
+	if status.LastSuccess.Before(status.LastAttempt.Add(-time.Second)) {
+		t.Errorf("expected recent success, got %+v", status)
+	}
+}
@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Estimators calibrated against the o200k_base and cl100k_base encodings.
+	TokenizerO200kEstimate  = "o200k_estimate"
+	TokenizerCL100kEstimate = "cl100k_estimate"
+
+	tokenizerHeuristicPrefix = "heuristic:"
+	tokenizerCharsPrefix     = "chars/"
+	defaultCharsPerToken     = 4.0
+)
+
+var ErrContextExceeded = errors.New("input exceeds model context")
+
+// Tokenizer counts tokens offline. Implementations must be safe for
+// concurrent use.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+var (
+	tokenizersMu sync.RWMutex
+	tokenizers   = map[string]Tokenizer{
+		TokenizerO200kEstimate:  &bpeEstimator{charsPerToken: 4.4, runesPerWideToken: 1.4},
+		TokenizerCL100kEstimate: &bpeEstimator{charsPerToken: 4.0, runesPerWideToken: 1.0},
+	}
+)
+
+// RegisterTokenizer makes a tokenizer available under name, replacing any
+// previous registration.
+func RegisterTokenizer(name string, tokenizer Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	tokenizers[name] = tokenizer
+}
+
+// GetTokenizer resolves a tokenizer spec such as "o200k_estimate" or
+// "heuristic:chars/3.5". An empty spec yields the default heuristic.
+func GetTokenizer(spec string) (Tokenizer, error) {
+	if spec == "" {
+		return charsTokenizer{charsPerToken: defaultCharsPerToken}, nil
+	}
+
+	if strings.HasPrefix(spec, tokenizerHeuristicPrefix) {
+		return parseHeuristicTokenizer(strings.TrimPrefix(spec, tokenizerHeuristicPrefix))
+	}
+
+	tokenizersMu.RLock()
+	tokenizer, ok := tokenizers[spec]
+	tokenizersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer: %s", spec)
+	}
+
+	return tokenizer, nil
+}
+
+func parseHeuristicTokenizer(rule string) (Tokenizer, error) {
+	if !strings.HasPrefix(rule, tokenizerCharsPrefix) {
+		return nil, fmt.Errorf("unsupported tokenizer heuristic: %s", rule)
+	}
+
+	ratio, err := strconv.ParseFloat(strings.TrimPrefix(rule, tokenizerCharsPrefix), 64)
+	if err != nil || ratio <= 0 || math.IsNaN(ratio) || math.IsInf(ratio, 0) {
+		return nil, fmt.Errorf("invalid tokenizer heuristic ratio: %s", rule)
+	}
+
+	return charsTokenizer{charsPerToken: ratio}, nil
+}
+
+func (m *Model) CountTokens(text string) (int, error) {
+	tokenizer, err := GetTokenizer(m.Tokenizer)
+	if err != nil {
+		return 0, fmt.Errorf("model %s: %w", m.Name, err)
+	}
+
+	return tokenizer.CountTokens(text), nil
+}
+
+// CheckContext counts the tokens in text and reports ErrContextExceeded when
+// they do not fit the model's max_input.
+func (m *Model) CheckContext(text string) (int, error) {
+	count, err := m.CountTokens(text)
+	if err != nil {
+		return 0, err
+	}
+
+	if m.APIs.ChatCompletion == nil || m.APIs.ChatCompletion.Context.MaxInput <= 0 {
+		return count, nil
+	}
+
+	if limit := m.APIs.ChatCompletion.Context.MaxInput; count > limit {
+		return count, fmt.Errorf("model %s: %d tokens > max_input %d: %w", m.Name, count, limit, ErrContextExceeded)
+	}
+
+	return count, nil
+}
+
+type charsTokenizer struct {
+	charsPerToken float64
+}
+
+func (t charsTokenizer) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / t.charsPerToken))
+}
+
+// bpeEstimator approximates byte-pair encodings without their merge tables.
+// Text is split the way the tiktoken pre-tokenizer splits it (words, digit
+// groups of three, punctuation runs, whitespace) and each piece is charged
+// according to the calibrated ratios.
+type bpeEstimator struct {
+	charsPerToken     float64
+	runesPerWideToken float64
+}
+
+func (e *bpeEstimator) CountTokens(text string) int {
+	var (
+		tokens  float64
+		word    int
+		digits  int
+		punct   int
+		spaces  int
+		newline bool
+	)
+
+	flush := func() {
+		if word > 0 {
+			// Common short words are a single token in both vocabularies.
+			tokens += math.Max(1, math.Ceil(float64(word-2)/e.charsPerToken))
+			word = 0
+		}
+		if digits > 0 {
+			tokens += math.Ceil(float64(digits) / 3)
+			digits = 0
+		}
+		if punct > 0 {
+			tokens += math.Ceil(float64(punct) / 2)
+			punct = 0
+		}
+		// A single space is merged into the following piece; longer runs and
+		// line breaks become tokens of their own.
+		if spaces > 1 || newline {
+			tokens++
+		}
+		spaces, newline = 0, false
+	}
+
+	for _, r := range text {
+		if !unicode.IsSpace(r) && (spaces > 0 || newline) {
+			flush()
+		}
+
+		switch {
+		case unicode.IsSpace(r):
+			if word > 0 || digits > 0 || punct > 0 {
+				flush()
+			}
+			spaces++
+			newline = newline || r == '\n'
+		case r > unicode.MaxLatin1 && !unicode.IsPunct(r):
+			flush()
+			tokens += 1 / e.runesPerWideToken
+		case unicode.IsLetter(r):
+			if digits > 0 || punct > 0 {
+				flush()
+			}
+			word++
+		case unicode.IsDigit(r):
+			if word > 0 || punct > 0 {
+				flush()
+			}
+			digits++
+		default:
+			if word > 0 || digits > 0 {
+				flush()
+			}
+			punct++
+		}
+	}
+	flush()
+
+	return int(math.Ceil(tokens))
+}
@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetTokenizer(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		text      string
+		want      int
+		wantError bool
+	}{
+		{
+			name: "default heuristic",
+			spec: "",
+			text: "abcdefgh",
+			want: 2,
+		},
+		{
+			name: "chars heuristic",
+			spec: "heuristic:chars/3.5",
+			text: "abcdefg",
+			want: 2,
+		},
+		{
+			name: "chars heuristic rounds up",
+			spec: "heuristic:chars/2",
+			text: "abc",
+			want: 2,
+		},
+		{
+			name: "empty text",
+			spec: TokenizerO200kEstimate,
+			text: "",
+			want: 0,
+		},
+		{
+			name:      "invalid ratio",
+			spec:      "heuristic:chars/0",
+			wantError: true,
+		},
+		{
+			name:      "NaN ratio",
+			spec:      "heuristic:chars/NaN",
+			wantError: true,
+		},
+		{
+			name:      "infinite ratio",
+			spec:      "heuristic:chars/+Inf",
+			wantError: true,
+		},
+		{
+			name:      "unsupported heuristic",
+			spec:      "heuristic:words/2",
+			wantError: true,
+		},
+		{
+			name:      "unknown encoding",
+			spec:      "p50k_base",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenizer, err := GetTokenizer(tt.spec)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tokenizer.CountTokens(tt.text); got != tt.want {
+				t.Errorf("CountTokens(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBPEEstimatorApproximation(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog. It was 2024, and the year was ending."
+
+	// tiktoken reports about 21 tokens for this sentence with both encodings.
+	for _, spec := range []string{TokenizerO200kEstimate, TokenizerCL100kEstimate} {
+		tokenizer, err := GetTokenizer(spec)
+		if err != nil {
+			t.Fatalf("GetTokenizer(%s) failed: %v", spec, err)
+		}
+
+		got := tokenizer.CountTokens(text)
+		if got < 17 || got > 25 {
+			t.Errorf("%s: expected roughly 21 tokens, got %d", spec, got)
+		}
+	}
+
+	cl100k, _ := GetTokenizer(TokenizerCL100kEstimate)
+	o200k, _ := GetTokenizer(TokenizerO200kEstimate)
+	cjk := strings.Repeat("你好世界", 10)
+	if o200k.CountTokens(cjk) >= cl100k.CountTokens(cjk) {
+		t.Errorf("expected o200k_estimate to count CJK text more densely than cl100k_estimate")
+	}
+}
+
+type fixedTokenizer int
+
+func (f fixedTokenizer) CountTokens(string) int {
+	return int(f)
+}
+
+func TestRegisterTokenizer(t *testing.T) {
+	RegisterTokenizer("test-fixed", fixedTokenizer(7))
+
+	model := &Model{Name: "test-model", Tokenizer: "test-fixed"}
+	count, err := model.CountTokens("anything")
+	if err != nil {
+		t.Fatalf("CountTokens() failed: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("expected 7 tokens, got %d", count)
+	}
+}
+
+func TestModelCheckContext(t *testing.T) {
+	var model Model
+	if err := yaml.Unmarshal([]byte(`
+name: tiny
+tokenizer: heuristic:chars/1
+apis:
+  chat_completion:
+    context:
+      max_input: 10
+      max_output: 5
+`), &model); err != nil {
+		t.Fatalf("unmarshal model: %v", err)
+	}
+
+	if count, err := model.CheckContext("0123456789"); err != nil || count != 10 {
+		t.Errorf("expected 10 tokens within limit, got %d, %v", count, err)
+	}
+
+	count, err := model.CheckContext("0123456789a")
+	if !errors.Is(err, ErrContextExceeded) {
+		t.Fatalf("expected ErrContextExceeded, got %v", err)
+	}
+	if count != 11 {
+		t.Errorf("expected 11 tokens, got %d", count)
+	}
+
+	copied := model.Copy()
+	if copied.Tokenizer != model.Tokenizer {
+		t.Errorf("copy lost tokenizer: %q", copied.Tokenizer)
+	}
+
+	model.Merge(&Model{Tokenizer: TokenizerO200kEstimate})
+	if model.Tokenizer != TokenizerO200kEstimate {
+		t.Errorf("merge did not replace tokenizer: %q", model.Tokenizer)
+	}
+}
+
+func TestModelValidateUnknownTokenizer(t *testing.T) {
+	model := &Model{Name: "test-model", Tokenizer: "unknown"}
+	if err := model.Validate(); err == nil {
+		t.Fatal("expected error for unknown tokenizer")
+	}
+}
@@ -1,30 +1,60 @@
 package registry
 
 import (
+	"context"
 	"sync"
-
-	"github.com/workpi-ai/go-utils/ghrelease"
+	"time"
 )
 
 type ProviderType string
 
 type AuthType string
 
+type AuthSchemeType string
+
 type APIFormat string
 
+type UpdateChannel string
+
+type DataSource string
+
+type ExportFormat string
+
+type ImportFormat string
+
+type ChangeKind string
+
+type DiscoveryKind string
+
 type Registry struct {
 	Providers map[string]*Provider
 
 	mu              sync.RWMutex
 	configDir       string
 	loader          *Loader
-	updater         *ghrelease.Updater
+	updater         *Updater
 	customProviders []*Provider
-	stopChan        chan struct{}
-	closeOnce       sync.Once
+	updateTimeout   time.Duration
+	overridesDir    string
+	onReload        func(ReloadEvent)
+	reloadMu        sync.Mutex
+	discovery       []*DiscoverySource
+	discoverRunMu   sync.Mutex
+	discoveredMu    sync.Mutex
+	discovered      map[string]map[string]*Model
+	versionInfo     VersionInfo
+	ctx             context.Context
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+	statusMu        sync.Mutex
+	status          UpdateStatus
 }
 
 type Metadata struct {
-	Version     string `json:"version"`
-	LastCheckAt string `json:"last_check_at"`
+	Version      string `json:"version"`
+	LastCheckAt  string `json:"last_check_at"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Source       string `json:"source,omitempty"`
+	Pinned       bool   `json:"pinned,omitempty"`
 }
@@ -1,32 +1,244 @@
 package registry
 
 import (
-	"strings"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
 
-	"github.com/workpi-ai/go-utils/ghrelease"
+const (
+	defaultRequestTimeout  = 3 * time.Second
+	defaultDownloadTimeout = 30 * time.Second
 )
 
-type providerPathTransformer struct {
-	destDir string
+// Updater installs bundles from an UpdateSource into a config dir. Every
+// network call is bound to the context passed to Update.
+type Updater struct {
+	config       UpdaterConfig
+	metadataFile string
+}
+
+type UpdaterConfig struct {
+	DestDir string
+	// Source defaults to the GitHub releases of the model-registry repository.
+	Source UpdateSource
+	// PublicKey, when set, requires bundles to ship a SHA256SUMS manifest
+	// signed with the matching ed25519 key.
+	PublicKey ed25519.PublicKey
+	// PublicKeyID is the minisign key id from ParseMinisignPublicKey.
+	PublicKeyID string
+	Policy      UpdatePolicy
+	// KeepVersions is how many installed versions are kept for rollback.
+	KeepVersions int
+	// CheckInterval makes Check skip the source when any process sharing
+	// DestDir checked more recently than this.
+	CheckInterval time.Duration
+}
+
+// NewUpdater returns an updater for destDir with the default source, policy
+// and retention.
+func NewUpdater(destDir string) (*Updater, error) {
+	return NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir})
+}
+
+func NewUpdaterWithConfig(config UpdaterConfig) (*Updater, error) {
+	if config.DestDir == "" {
+		return nil, fmt.Errorf("destination dir cannot be empty")
+	}
+	if config.Source == nil {
+		config.Source = NewGitHubSource(repoOwner, repoName)
+	}
+	if err := config.Policy.Validate(); err != nil {
+		return nil, err
+	}
+	config.Policy = config.Policy.withDefaults()
+	if config.KeepVersions <= 0 {
+		config.KeepVersions = DefaultKeepVersions
+	}
+
+	return &Updater{
+		config:       config,
+		metadataFile: filepath.Join(config.DestDir, versionFile),
+	}, nil
 }
 
-func (t *providerPathTransformer) Transform(filename string) string {
-	if strings.Contains(filename, providersDir+"/") && strings.HasSuffix(filename, yamlExt) {
-		return filename
+// Update checks the source now, first waiting for an update running in
+// another process that shares DestDir.
+func (u *Updater) Update(ctx context.Context) error {
+	lock, err := u.lock(ctx)
+	if err != nil {
+		return err
 	}
-	return ""
+	defer lock.Unlock()
+
+	return u.update(ctx)
 }
 
-func NewUpdater(destDir string) (*ghrelease.Updater, error) {
-	return ghrelease.NewUpdater(ghrelease.UpdaterConfig{
-		RepoOwner:    repoOwner,
-		RepoName:     repoName,
-		MetadataFile: destDir + "/" + versionFile,
-		Targets: []ghrelease.ExtractTarget{
-			{
-				PathTransformer: &providerPathTransformer{destDir: destDir},
-				DestDir:         destDir,
-			},
-		},
+// Check is Update for periodic callers: it returns ErrUpdateInProgress rather
+// than waiting, and skips checks within CheckInterval.
+func (u *Updater) Check(ctx context.Context) error {
+	if err := os.MkdirAll(u.config.DestDir, defaultDirPerm); err != nil {
+		return err
+	}
+
+	lock, err := tryLock(u.lockPath(), true)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return ErrUpdateInProgress
+	}
+	defer lock.Unlock()
+
+	lastCheck := parseCheckTime(readMetadata(u.metadataFile).LastCheckAt)
+	if time.Since(lastCheck) < u.config.CheckInterval && !u.needsRedownload() {
+		return nil
+	}
+
+	return u.update(ctx)
+}
+
+func (u *Updater) update(ctx context.Context) error {
+	if err := u.migrateLegacy(); err != nil {
+		return err
+	}
+
+	current := readMetadata(u.metadataFile)
+	if current.Pinned {
+		return nil
+	}
+	if u.needsRedownload() {
+		current = Metadata{}
+	}
+
+	if pin := u.config.Policy.Pin; pin != "" && pin != current.Version {
+		if installed, ok := u.installed(pin); ok {
+			return u.activate(installed, false)
+		}
+	}
+
+	source := u.config.Source
+	bundle, err := u.fetch(ctx, current)
+	if err != nil {
+		return fmt.Errorf("fetch from %s: %w", source.Name(), err)
+	}
+
+	if bundle == nil {
+		current.LastCheckAt = time.Now().Format(time.RFC3339)
+		if current.Source != source.Name() {
+			current.Source = source.Name()
+			current.ETag, current.LastModified = "", ""
+		}
+		return writeMetadata(u.metadataFile, current)
+	}
+
+	if err := verifyBundle(bundle, u.config.PublicKey, u.config.PublicKeyID); err != nil {
+		return fmt.Errorf("verify %s: %w", bundle.Version, err)
+	}
+
+	installed, err := u.stage(bundle, Metadata{
+		Version:      bundle.Version,
+		LastCheckAt:  time.Now().Format(time.RFC3339),
+		ETag:         bundle.ETag,
+		LastModified: bundle.LastModified,
+		Source:       source.Name(),
 	})
+	if err != nil {
+		return fmt.Errorf("install %s: %w", bundle.Version, err)
+	}
+
+	if err := u.activate(installed, false); err != nil {
+		return err
+	}
+
+	return u.prune()
+}
+
+// fetch asks the source for a bundle allowed by the update policy. Sources
+// that list releases get to choose among them; others can only be checked.
+func (u *Updater) fetch(ctx context.Context, current Metadata) (*Bundle, error) {
+	policy := u.config.Policy
+
+	if lister, ok := u.config.Source.(ReleaseLister); ok {
+		releases, err := lister.ListReleases(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		release, ok := policy.selectRelease(releases)
+		if !ok {
+			return nil, fmt.Errorf("%w: none of %d releases match", ErrVersionRejected, len(releases))
+		}
+		if release.Version == current.Version {
+			return nil, nil
+		}
+
+		return lister.FetchRelease(ctx, release)
+	}
+
+	// Validators belong to the source that issued them; another URL must
+	// not answer 304 on the strength of them.
+	if current.Source != u.config.Source.Name() {
+		current.ETag, current.LastModified = "", ""
+	}
+
+	bundle, err := u.config.Source.Fetch(ctx, current)
+	if err != nil || bundle == nil {
+		return bundle, err
+	}
+	if err := policy.check(Release{Version: bundle.Version}); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (u *Updater) lock(ctx context.Context) (*fileLock, error) {
+	if err := os.MkdirAll(u.config.DestDir, defaultDirPerm); err != nil {
+		return nil, err
+	}
+	return acquireLock(ctx, u.lockPath(), true)
+}
+
+func (u *Updater) lockPath() string {
+	return filepath.Join(u.config.DestDir, updateLockFile)
+}
+
+func (u *Updater) needsRedownload() bool {
+	entries, err := os.ReadDir(activeProvidersDir(u.config.DestDir))
+	return err != nil || len(entries) == 0
+}
+
+func readMetadata(path string) Metadata {
+	var metadata Metadata
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return metadata
+	}
+
+	_ = json.Unmarshal(data, &metadata)
+	return metadata
+}
+
+func writeMetadata(path string, metadata Metadata) error {
+	if err := os.MkdirAll(filepath.Dir(path), defaultDirPerm); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write and rename so other processes never read a partial file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, defaultFilePerm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
 }
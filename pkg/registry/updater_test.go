@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func createTestZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("write zip entry: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newTestGitHubSource(t *testing.T, server *httptest.Server) *GitHubSource {
+	t.Helper()
+
+	source := NewGitHubSource(repoOwner, repoName)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+	source.Client.BaseURL = baseURL
+	return source
+}
+
+func newFakeGitHub(t *testing.T, version string, archive []byte) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/repos/"+repoOwner+"/"+repoName+"/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": %q, "zipball_url": %q}`, version, server.URL+"/zipball")
+	})
+	mux.HandleFunc("/repos/"+repoOwner+"/"+repoName+"/releases", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"tag_name": %q, "zipball_url": %q}]`, version, server.URL+"/zipball")
+	})
+	mux.HandleFunc("/zipball", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+const testProviderYAML = `name: mirror
+type: api
+auth_type: api_key
+api_key: MIRROR_API_KEY
+base_url: https://mirror.example.com/v1
+`
+
+const testModelYAML = `name: mirror-model
+apis:
+  chat_completion:
+    api_format: openai
+    context:
+      max_input: 1000
+      max_output: 100
+    parameters:
+      max_tokens: 100
+`
+
+// testModel returns testModelYAML renamed to name, with edit applied.
+func testModel(t *testing.T, name string, edit func(*Model)) *fstest.MapFile {
+	t.Helper()
+
+	var model Model
+	if err := yaml.Unmarshal([]byte(testModelYAML), &model); err != nil {
+		t.Fatal(err)
+	}
+	model.Name = name
+	if edit != nil {
+		edit(&model)
+	}
+
+	data, err := yaml.Marshal(&model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fstest.MapFile{Data: data}
+}
+
+func TestUpdaterUpdate(t *testing.T) {
+	archive := createTestZip(t, map[string]string{
+		"registry-v1/README.md":                                 "ignored",
+		"registry-v1/providers/mirror/provider.yaml":            testProviderYAML,
+		"registry-v1/providers/mirror/models/mirror-model.yaml": testModelYAML,
+	})
+	server := newFakeGitHub(t, "v1.0.0", archive)
+
+	destDir := t.TempDir()
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: newTestGitHubSource(t, server)})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(activeProvidersDir(destDir), "mirror", "models", "mirror-model.yaml")); err != nil {
+		t.Errorf("expected model file to be extracted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "README.md")); !os.IsNotExist(err) {
+		t.Errorf("expected README.md to be skipped, got %v", err)
+	}
+	metadata := readMetadata(filepath.Join(destDir, versionFile))
+	if metadata.Version != "v1.0.0" {
+		t.Errorf("expected metadata version v1.0.0, got %q", metadata.Version)
+	}
+	if metadata.Source != "github:"+repoOwner+"/"+repoName {
+		t.Errorf("unexpected metadata source %q", metadata.Source)
+	}
+}
+
+func TestNewUpdater(t *testing.T) {
+	if _, err := NewUpdater(""); err == nil {
+		t.Fatal("expected error for empty destination dir")
+	}
+
+	updater, err := NewUpdater(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewUpdater() failed: %v", err)
+	}
+	if updater.config.Source == nil || updater.config.KeepVersions != DefaultKeepVersions {
+		t.Errorf("expected default source and retention, got %+v", updater.config)
+	}
+}
+
+func TestUpdaterRejectsPathTraversal(t *testing.T) {
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: t.TempDir(), Source: NewDirSource(t.TempDir())})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	bundle := &Bundle{Files: map[string][]byte{"providers/../../evil.yaml": []byte("name: evil")}}
+	if _, err := updater.stage(bundle, Metadata{}); err == nil {
+		t.Fatal("expected error for path traversal")
+	}
+}
+
+func TestForceUpdateContextCanceled(t *testing.T) {
+	reg, err := New(Options{ConfigDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := reg.ForceUpdateContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCloseAbortsInFlightUpdate(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	configDir := t.TempDir()
+	reg, err := New(Options{ConfigDir: configDir})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	source := newTestGitHubSource(t, server)
+	source.RequestTimeout = time.Minute
+	reg.updater, err = NewUpdaterWithConfig(UpdaterConfig{DestDir: configDir, Source: source})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	reg.wg.Add(1)
+	go reg.autoUpdateLoop(time.Hour)
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		_ = reg.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not abort the in-flight update")
+	}
+}
@@ -7,6 +7,11 @@ func SetIfNotZero[T comparable](target *T, source T) {
 	}
 }
 
+// Ptr returns a pointer to v, for setting ParamOverrides fields.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 func CopySlice[T any](src []T) []T {
 	if len(src) == 0 {
 		return nil
@@ -15,3 +20,27 @@ func CopySlice[T any](src []T) []T {
 	copy(dst, src)
 	return dst
 }
+
+func CopyMap[K comparable, V any](src map[K]V) map[K]V {
+	if len(src) == 0 {
+		return nil
+	}
+	dst := make(map[K]V, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func MergeMap[K comparable, V any](dst, src map[K]V) map[K]V {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[K]V, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
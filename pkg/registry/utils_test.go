@@ -158,3 +158,32 @@ func TestSetIfNotZeroWithInt(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyMap(t *testing.T) {
+	if got := CopyMap(map[string]string{}); got != nil {
+		t.Errorf("expected nil for empty map, got %v", got)
+	}
+
+	src := map[string]string{"a": "1"}
+	dst := CopyMap(src)
+	dst["a"] = "2"
+	if src["a"] != "1" {
+		t.Errorf("copy shares storage with source: %v", src)
+	}
+}
+
+func TestMergeMap(t *testing.T) {
+	merged := MergeMap(nil, map[string]string{"a": "1"})
+	if merged["a"] != "1" {
+		t.Errorf("expected a=1, got %v", merged)
+	}
+
+	merged = MergeMap(merged, map[string]string{"a": "2", "b": "3"})
+	if merged["a"] != "2" || merged["b"] != "3" {
+		t.Errorf("unexpected merge result: %v", merged)
+	}
+
+	if got := MergeMap(merged, nil); len(got) != 2 {
+		t.Errorf("expected merge with nil to keep entries, got %v", got)
+	}
+}
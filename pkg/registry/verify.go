@@ -0,0 +1,206 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	manifestFile  = "SHA256SUMS"
+	signatureFile = manifestFile + ".sig"
+
+	minisignAlgEd      = "Ed"
+	minisignAlgHashed  = "ED"
+	minisignKeyIDLen   = 8
+	minisignHeaderSize = 2 + minisignKeyIDLen
+)
+
+// ParsePublicKey accepts a base64-encoded raw ed25519 public key or a
+// minisign public key (the "RW..." line of a minisign .pub file).
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, _, err := ParseMinisignPublicKey(s)
+	return key, err
+}
+
+// ParseMinisignPublicKey is ParsePublicKey that also returns the minisign key
+// id for PublicKeyID. The id is empty for raw keys.
+func ParseMinisignPublicKey(s string) (ed25519.PublicKey, string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, "", fmt.Errorf("decode public key: %w", err)
+	}
+
+	switch len(raw) {
+	case ed25519.PublicKeySize:
+		return ed25519.PublicKey(raw), "", nil
+	case minisignHeaderSize + ed25519.PublicKeySize:
+		if string(raw[:2]) != minisignAlgEd {
+			return nil, "", fmt.Errorf("unsupported minisign key algorithm: %q", raw[:2])
+		}
+		return ed25519.PublicKey(raw[minisignHeaderSize:]), minisignKeyID(raw[2:minisignHeaderSize]), nil
+	default:
+		return nil, "", fmt.Errorf("invalid public key length: %d", len(raw))
+	}
+}
+
+// minisignKeyID renders a key id as minisign prints it.
+func minisignKeyID(raw []byte) string {
+	reversed := make([]byte, len(raw))
+	for i, b := range raw {
+		reversed[len(raw)-1-i] = b
+	}
+	return strings.ToUpper(hex.EncodeToString(reversed))
+}
+
+// verifyBundle checks the provider files against the SHA256SUMS manifest,
+// which must be signed when key is set.
+func verifyBundle(bundle *Bundle, key ed25519.PublicKey, keyID string) error {
+	manifest, hasManifest := bundle.Files[manifestFile]
+
+	if key != nil {
+		if !hasManifest {
+			return fmt.Errorf("bundle has no %s", manifestFile)
+		}
+		signature, ok := bundle.Files[signatureFile]
+		if !ok {
+			return fmt.Errorf("bundle has no %s", signatureFile)
+		}
+		if err := verifySignature(key, keyID, manifest, signature); err != nil {
+			return err
+		}
+	}
+
+	if !hasManifest {
+		return nil
+	}
+
+	sums, err := parseManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(bundle.Files))
+	for name := range bundle.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == manifestFile || name == signatureFile {
+			continue
+		}
+
+		want, ok := sums[name]
+		if !ok {
+			return fmt.Errorf("%s is not listed in %s", name, manifestFile)
+		}
+		if got := sha256.Sum256(bundle.Files[name]); hex.EncodeToString(got[:]) != want {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		delete(sums, name)
+	}
+
+	for name := range sums {
+		if _, ok := bundlePath(name); ok {
+			return fmt.Errorf("%s listed in %s is missing from bundle", name, manifestFile)
+		}
+	}
+
+	return nil
+}
+
+func parseManifest(data []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		sum, name, ok := strings.Cut(line, " ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid %s line: %q", manifestFile, line)
+		}
+
+		// sha256sum marks binary mode with a leading '*'.
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		sums[strings.TrimPrefix(name, "./")] = strings.ToLower(sum)
+	}
+
+	return sums, scanner.Err()
+}
+
+// verifySignature accepts a raw or base64 signature, or a minisign signature
+// file (Ed or prehashed ED) made with keyID when that is set.
+func verifySignature(key ed25519.PublicKey, keyID string, message, signature []byte) error {
+	sig, err := decodeSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	if keyID != "" && sig.keyID != "" && !strings.EqualFold(keyID, sig.keyID) {
+		return fmt.Errorf("%s is signed with key %s, expected %s", manifestFile, sig.keyID, keyID)
+	}
+	if sig.prehashed {
+		digest := blake2b.Sum512(message)
+		message = digest[:]
+	}
+
+	if !ed25519.Verify(key, message, sig.signature) {
+		return fmt.Errorf("invalid %s signature", manifestFile)
+	}
+
+	return nil
+}
+
+type decodedSignature struct {
+	signature []byte
+	keyID     string
+	prehashed bool
+}
+
+func decodeSignature(data []byte) (decodedSignature, error) {
+	if len(data) == ed25519.SignatureSize {
+		return decodedSignature{signature: data}, nil
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) == 1 {
+		sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+		if err != nil || len(sig) != ed25519.SignatureSize {
+			return decodedSignature{}, fmt.Errorf("invalid %s", signatureFile)
+		}
+		return decodedSignature{signature: sig}, nil
+	}
+
+	// minisign: "untrusted comment: ..." followed by the base64 signature.
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != minisignHeaderSize+ed25519.SignatureSize {
+		return decodedSignature{}, fmt.Errorf("invalid minisign signature in %s", signatureFile)
+	}
+
+	decoded := decodedSignature{
+		signature: raw[minisignHeaderSize:],
+		keyID:     minisignKeyID(raw[2:minisignHeaderSize]),
+	}
+	switch string(raw[:2]) {
+	case minisignAlgEd:
+	case minisignAlgHashed:
+		decoded.prehashed = true
+	default:
+		return decodedSignature{}, fmt.Errorf("unsupported minisign signature algorithm: %q", raw[:2])
+	}
+
+	return decoded, nil
+}
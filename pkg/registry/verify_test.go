@@ -0,0 +1,227 @@
+package registry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+func buildManifest(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		sum := sha256.Sum256([]byte(files[name]))
+		fmt.Fprintf(&b, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+	return b.String()
+}
+
+func newSignedBundle(t *testing.T, priv ed25519.PrivateKey, files map[string]string) *Bundle {
+	t.Helper()
+
+	manifest := buildManifest(files)
+	bundle := &Bundle{Version: "v1.0.0", Files: map[string][]byte{
+		manifestFile:  []byte(manifest),
+		signatureFile: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(manifest)))),
+	}}
+	for name, content := range files {
+		bundle.Files[name] = []byte(content)
+	}
+	return bundle
+}
+
+func TestVerifyBundle(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	files := map[string]string{
+		"providers/mirror/provider.yaml":            testProviderYAML,
+		"providers/mirror/models/mirror-model.yaml": testModelYAML,
+	}
+
+	t.Run("valid signed bundle", func(t *testing.T) {
+		if err := verifyBundle(newSignedBundle(t, priv, files), pub, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		if err := verifyBundle(newSignedBundle(t, priv, files), otherPub, ""); err == nil {
+			t.Fatal("expected signature error")
+		}
+	})
+
+	t.Run("tampered file", func(t *testing.T) {
+		bundle := newSignedBundle(t, priv, files)
+		bundle.Files["providers/mirror/provider.yaml"] = []byte("name: evil")
+		if err := verifyBundle(bundle, pub, ""); err == nil {
+			t.Fatal("expected checksum error")
+		}
+	})
+
+	t.Run("unlisted file", func(t *testing.T) {
+		bundle := newSignedBundle(t, priv, files)
+		bundle.Files["providers/mirror/models/extra.yaml"] = []byte("name: extra")
+		if err := verifyBundle(bundle, pub, ""); err == nil {
+			t.Fatal("expected unlisted file error")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		bundle := newSignedBundle(t, priv, files)
+		delete(bundle.Files, "providers/mirror/models/mirror-model.yaml")
+		if err := verifyBundle(bundle, pub, ""); err == nil {
+			t.Fatal("expected missing file error")
+		}
+	})
+
+	t.Run("key requires manifest", func(t *testing.T) {
+		bundle := &Bundle{Files: map[string][]byte{"providers/mirror/provider.yaml": []byte(testProviderYAML)}}
+		if err := verifyBundle(bundle, pub, ""); err == nil {
+			t.Fatal("expected error for unsigned bundle")
+		}
+		if err := verifyBundle(bundle, nil, ""); err != nil {
+			t.Fatalf("expected unsigned bundle to pass without key: %v", err)
+		}
+	})
+}
+
+func TestMinisignFormats(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyID := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	encodedKey := base64.StdEncoding.EncodeToString(append(append([]byte(minisignAlgEd), keyID...), pub...))
+
+	parsed, parsedID, err := ParseMinisignPublicKey(encodedKey)
+	if err != nil {
+		t.Fatalf("ParseMinisignPublicKey() failed: %v", err)
+	}
+	if !parsed.Equal(pub) {
+		t.Fatal("parsed minisign key does not match")
+	}
+	if parsedID != "0807060504030201" {
+		t.Errorf("expected key id 0807060504030201, got %s", parsedID)
+	}
+
+	message := []byte("manifest")
+	digest := blake2b.Sum512(message)
+	sigFile := func(alg string, id []byte, sig []byte) []byte {
+		raw := append(append([]byte(alg), id...), sig...)
+		return []byte("untrusted comment: signature\n" + base64.StdEncoding.EncodeToString(raw) +
+			"\ntrusted comment: timestamp:0\nAAAA\n")
+	}
+
+	tests := []struct {
+		name      string
+		keyID     string
+		message   []byte
+		signature []byte
+		wantError bool
+	}{
+		{name: "legacy", keyID: parsedID, message: message, signature: sigFile(minisignAlgEd, keyID, ed25519.Sign(priv, message))},
+		{name: "prehashed", keyID: parsedID, message: message, signature: sigFile(minisignAlgHashed, keyID, ed25519.Sign(priv, digest[:]))},
+		{name: "no key id pinned", message: message, signature: sigFile(minisignAlgHashed, keyID, ed25519.Sign(priv, digest[:]))},
+		{name: "legacy mismatch", keyID: parsedID, message: []byte("other"), signature: sigFile(minisignAlgEd, keyID, ed25519.Sign(priv, message)), wantError: true},
+		{name: "prehashed mismatch", keyID: parsedID, message: []byte("other"), signature: sigFile(minisignAlgHashed, keyID, ed25519.Sign(priv, digest[:])), wantError: true},
+		{name: "prehash not applied", keyID: parsedID, message: message, signature: sigFile(minisignAlgHashed, keyID, ed25519.Sign(priv, message)), wantError: true},
+		{name: "other key id", keyID: parsedID, message: message, signature: sigFile(minisignAlgEd, []byte("87654321"), ed25519.Sign(priv, message)), wantError: true},
+		{name: "unknown algorithm", keyID: parsedID, message: message, signature: sigFile("Xx", keyID, ed25519.Sign(priv, message)), wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySignature(parsed, tt.keyID, tt.message, tt.signature)
+			if tt.wantError && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantError && err != nil {
+				t.Fatalf("verifySignature() failed: %v", err)
+			}
+		})
+	}
+
+	rawKey, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil || !rawKey.Equal(pub) {
+		t.Fatalf("ParsePublicKey() raw key = %v, %v", rawKey, err)
+	}
+}
+
+func TestUpdaterKeepsCacheOnVerificationFailure(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"providers/mirror/provider.yaml": testProviderYAML,
+	}
+
+	writeSource := func(t *testing.T, dir string, bundle *Bundle) {
+		t.Helper()
+		for name, content := range bundle.Files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	sourceDir := t.TempDir()
+	writeSource(t, sourceDir, newSignedBundle(t, priv, files))
+
+	destDir := t.TempDir()
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{
+		DestDir:   destDir,
+		Source:    NewDirSource(sourceDir),
+		PublicKey: pub,
+	})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	installed := readMetadata(filepath.Join(destDir, versionFile)).Version
+
+	if err := os.WriteFile(filepath.Join(sourceDir, "providers/mirror/provider.yaml"), []byte("name: evil\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := updater.Update(context.Background()); err == nil {
+		t.Fatal("expected verification error for tampered bundle")
+	}
+
+	if version := readMetadata(filepath.Join(destDir, versionFile)).Version; version != installed {
+		t.Errorf("expected cached version %s to be kept, got %s", installed, version)
+	}
+	data, err := os.ReadFile(filepath.Join(activeProvidersDir(destDir), "mirror", providerYAML))
+	if err != nil || string(data) != testProviderYAML {
+		t.Errorf("expected cached provider to be kept, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,401 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	versionsDir         = "versions"
+	versionRecordFile   = "version.json"
+	currentFile         = "current"
+	stagingPrefix       = ".staging-"
+	legacyVersionName   = "legacy"
+	DefaultKeepVersions = 3
+)
+
+var unsafeVersionChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// InstalledVersion is a registry bundle kept under ConfigDir/versions.
+type InstalledVersion struct {
+	Metadata
+	Dir         string    `json:"-"`
+	InstalledAt time.Time `json:"installed_at"`
+	Active      bool      `json:"-"`
+}
+
+// Versions lists installed bundles, newest first.
+func (u *Updater) Versions() ([]InstalledVersion, error) {
+	entries, err := os.ReadDir(u.versionsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	active := u.activeDir()
+	pinned := readMetadata(u.metadataFile).Pinned
+
+	var versions []InstalledVersion
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), stagingPrefix) {
+			continue
+		}
+
+		version := readVersionRecord(filepath.Join(u.versionsPath(), entry.Name()))
+		version.Dir = entry.Name()
+		version.Active = entry.Name() == active
+		version.Pinned = version.Active && pinned
+		versions = append(versions, version)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].InstalledAt.After(versions[j].InstalledAt)
+	})
+
+	return versions, nil
+}
+
+// Rollback activates the version installed before the active one and pins
+// it so the next update does not reinstall the release being reverted.
+func (u *Updater) Rollback() error {
+	lock, err := u.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := u.migrateLegacy(); err != nil {
+		return err
+	}
+
+	versions, err := u.Versions()
+	if err != nil {
+		return err
+	}
+
+	for i, version := range versions {
+		if version.Active {
+			if i+1 >= len(versions) {
+				return fmt.Errorf("no version installed before %s", version.Version)
+			}
+			return u.activate(versions[i+1], true)
+		}
+	}
+
+	return fmt.Errorf("no active version to roll back from")
+}
+
+// Pin activates an installed version, matched by version or directory name,
+// and stops updates until Unpin is called.
+func (u *Updater) Pin(version string) error {
+	lock, err := u.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := u.migrateLegacy(); err != nil {
+		return err
+	}
+
+	installed, ok := u.installed(version)
+	if !ok {
+		return fmt.Errorf("version %s is not installed", version)
+	}
+
+	return u.activate(installed, true)
+}
+
+func (u *Updater) Unpin() error {
+	lock, err := u.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	metadata := readMetadata(u.metadataFile)
+	metadata.Pinned = false
+	return writeMetadata(u.metadataFile, metadata)
+}
+
+// stage writes and validates the bundle in a staging dir, then moves it
+// into place under versions/.
+func (u *Updater) stage(bundle *Bundle, metadata Metadata) (InstalledVersion, error) {
+	var installed InstalledVersion
+
+	if err := os.MkdirAll(u.versionsPath(), defaultDirPerm); err != nil {
+		return installed, err
+	}
+
+	staging, err := os.MkdirTemp(u.versionsPath(), stagingPrefix)
+	if err != nil {
+		return installed, err
+	}
+	defer os.RemoveAll(staging)
+
+	root := staging + string(filepath.Separator)
+	for name, content := range bundle.Files {
+		if !strings.HasPrefix(name, providersDir+"/") {
+			continue
+		}
+
+		fullPath := filepath.Join(staging, filepath.FromSlash(name))
+		if !strings.HasPrefix(fullPath, root) {
+			return installed, fmt.Errorf("illegal file path in bundle: %s", name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), defaultDirPerm); err != nil {
+			return installed, err
+		}
+		if err := os.WriteFile(fullPath, content, defaultFilePerm); err != nil {
+			return installed, err
+		}
+	}
+
+	if err := NewLoader(u.config.DestDir).ValidateDir(filepath.Join(staging, providersDir)); err != nil {
+		return installed, fmt.Errorf("validate: %w", err)
+	}
+
+	installed = InstalledVersion{
+		Metadata:    metadata,
+		Dir:         versionDirName(metadata.Version),
+		InstalledAt: time.Now(),
+	}
+	if err := writeVersionRecord(staging, installed); err != nil {
+		return installed, err
+	}
+
+	final := filepath.Join(u.versionsPath(), installed.Dir)
+	if _, err := os.Stat(final); err == nil {
+		return u.replaceVersion(staging, final, installed)
+	}
+	if err := os.Rename(staging, final); err != nil {
+		return installed, err
+	}
+
+	return installed, nil
+}
+
+// replaceVersion reinstalls a version already on disk. A copy that still
+// validates is kept; a broken one is moved aside so final always exists.
+func (u *Updater) replaceVersion(staging, final string, installed InstalledVersion) (InstalledVersion, error) {
+	if err := NewLoader(u.config.DestDir).ValidateDir(filepath.Join(final, providersDir)); err == nil {
+		return installed, writeVersionRecord(final, installed)
+	}
+
+	aside, err := os.MkdirTemp(u.versionsPath(), stagingPrefix)
+	if err != nil {
+		return installed, err
+	}
+	defer os.RemoveAll(aside)
+
+	old := filepath.Join(aside, installed.Dir)
+	if err := os.Rename(final, old); err != nil {
+		return installed, err
+	}
+	if err := os.Rename(staging, final); err != nil {
+		_ = os.Rename(old, final)
+		return installed, err
+	}
+
+	return installed, nil
+}
+
+// activate points ConfigDir/current at an installed version, replacing the
+// pointer file with a rename.
+func (u *Updater) activate(version InstalledVersion, pinned bool) error {
+	if err := u.migrateLegacy(); err != nil {
+		return err
+	}
+
+	if err := u.setActive(version.Dir); err != nil {
+		return fmt.Errorf("activate %s: %w", version.Dir, err)
+	}
+
+	// Keep the most recent check time; a rolled back version carries the
+	// time it was installed.
+	metadata := version.Metadata
+	previous := readMetadata(u.metadataFile).LastCheckAt
+	if parseCheckTime(metadata.LastCheckAt).Before(parseCheckTime(previous)) {
+		metadata.LastCheckAt = previous
+	}
+	metadata.Pinned = pinned
+
+	return writeMetadata(u.metadataFile, metadata)
+}
+
+func (u *Updater) setActive(dir string) error {
+	pointer := filepath.Join(u.config.DestDir, currentFile)
+	tmp := pointer + ".tmp"
+	if err := os.WriteFile(tmp, []byte(dir+"\n"), defaultFilePerm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, pointer); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// prune removes the oldest versions beyond KeepVersions, never the active one.
+func (u *Updater) prune() error {
+	// Skip pruning while another process is loading; the next update retries.
+	lock, err := tryLock(filepath.Join(u.config.DestDir, readLockFile), true)
+	if err != nil || lock == nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	versions, err := u.Versions()
+	if err != nil {
+		return err
+	}
+
+	kept := 0
+	for _, version := range versions {
+		if version.Active || kept < u.config.KeepVersions {
+			kept++
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(u.versionsPath(), version.Dir)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateLegacy moves a providers dir or symlink left by older releases
+// under versions/ and the current pointer file.
+func (u *Updater) migrateLegacy() error {
+	link := filepath.Join(u.config.DestDir, providersDir)
+	stat, err := os.Lstat(link)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if stat.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(link)
+		if err != nil {
+			return err
+		}
+		if dir := filepath.Base(filepath.Dir(target)); activeVersion(u.config.DestDir) == "" {
+			if err := u.setActive(dir); err != nil {
+				return err
+			}
+		}
+		return os.Remove(link)
+	}
+
+	metadata := readMetadata(u.metadataFile)
+	version := InstalledVersion{
+		Metadata:    metadata,
+		Dir:         versionDirName(metadata.Version),
+		InstalledAt: stat.ModTime(),
+	}
+
+	dir := filepath.Join(u.versionsPath(), version.Dir)
+	if err := os.MkdirAll(dir, defaultDirPerm); err != nil {
+		return err
+	}
+	if err := os.Rename(link, filepath.Join(dir, providersDir)); err != nil {
+		return fmt.Errorf("migrate %s: %w", link, err)
+	}
+	if err := writeVersionRecord(dir, version); err != nil {
+		return err
+	}
+
+	return u.activate(version, metadata.Pinned)
+}
+
+// installed finds an installed version by version or directory name.
+func (u *Updater) installed(version string) (InstalledVersion, bool) {
+	versions, _ := u.Versions()
+	for _, installed := range versions {
+		if installed.Version == version || installed.Dir == version {
+			return installed, true
+		}
+	}
+	return InstalledVersion{}, false
+}
+
+func (u *Updater) versionsPath() string {
+	return filepath.Join(u.config.DestDir, versionsDir)
+}
+
+func (u *Updater) activeDir() string {
+	return activeVersion(u.config.DestDir)
+}
+
+// activeVersion reads the version dir named by the current pointer file.
+func activeVersion(configDir string) string {
+	data, err := os.ReadFile(filepath.Join(configDir, currentFile))
+	if err != nil {
+		return ""
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
+
+// activeProvidersDir resolves the providers tree in use under configDir:
+// the active version, or a providers dir written by older releases.
+func activeProvidersDir(configDir string) string {
+	if name := activeVersion(configDir); name != "" {
+		return filepath.Join(configDir, versionsDir, name, providersDir)
+	}
+	return filepath.Join(configDir, providersDir)
+}
+
+func versionDirName(version string) string {
+	name := strings.Trim(unsafeVersionChars.ReplaceAllString(version, "-"), "-.")
+	if name == "" {
+		return legacyVersionName
+	}
+	return name
+}
+
+func readVersionRecord(dir string) InstalledVersion {
+	var version InstalledVersion
+
+	data, err := os.ReadFile(filepath.Join(dir, versionRecordFile))
+	if err == nil {
+		_ = json.Unmarshal(data, &version)
+	}
+
+	if version.InstalledAt.IsZero() {
+		if stat, err := os.Stat(dir); err == nil {
+			version.InstalledAt = stat.ModTime()
+		}
+	}
+
+	return version
+}
+
+func writeVersionRecord(dir string, version InstalledVersion) error {
+	data, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, versionRecordFile), data, defaultFilePerm)
+}
+
+func parseCheckTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
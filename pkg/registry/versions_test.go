@@ -0,0 +1,281 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeProviderTree(t *testing.T, dir, content string) {
+	t.Helper()
+
+	providerDir := filepath.Join(dir, providersDir, "mirror")
+	if err := os.MkdirAll(filepath.Join(providerDir, "models"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(providerDir, providerYAML), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(providerDir, "models", "mirror-model.yaml"), []byte(testModelYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func activeProviderYAML(t *testing.T, destDir string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(activeProvidersDir(destDir), "mirror", providerYAML))
+	if err != nil {
+		t.Fatalf("read active provider: %v", err)
+	}
+	return string(data)
+}
+
+func TestUpdaterStagedInstallAndRollback(t *testing.T) {
+	sourceDir := t.TempDir()
+	destDir := t.TempDir()
+
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: NewDirSource(sourceDir), KeepVersions: 2})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	releases := []string{
+		testProviderYAML + "description: first\n",
+		testProviderYAML + "description: second\n",
+		testProviderYAML + "description: third\n",
+	}
+	var installed []string
+	for _, release := range releases {
+		writeProviderTree(t, sourceDir, release)
+		if err := updater.Update(context.Background()); err != nil {
+			t.Fatalf("Update() failed: %v", err)
+		}
+		installed = append(installed, readMetadata(filepath.Join(destDir, versionFile)).Version)
+	}
+
+	if got := activeProviderYAML(t, destDir); got != releases[2] {
+		t.Fatalf("expected third release active, got %q", got)
+	}
+
+	versions, err := updater.Versions()
+	if err != nil {
+		t.Fatalf("Versions() failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 kept versions, got %d", len(versions))
+	}
+	if !versions[0].Active || versions[0].Version != installed[2] {
+		t.Errorf("expected newest version to be active, got %+v", versions[0])
+	}
+
+	if err := updater.Rollback(); err != nil {
+		t.Fatalf("Rollback() failed: %v", err)
+	}
+	if got := activeProviderYAML(t, destDir); got != releases[1] {
+		t.Fatalf("expected second release after rollback, got %q", got)
+	}
+
+	metadata := readMetadata(filepath.Join(destDir, versionFile))
+	if !metadata.Pinned || metadata.Version != installed[1] {
+		t.Errorf("expected pinned %s, got %+v", installed[1], metadata)
+	}
+
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if got := activeProviderYAML(t, destDir); got != releases[1] {
+		t.Errorf("expected pinned version to survive update, got %q", got)
+	}
+
+	if err := updater.Rollback(); err == nil {
+		t.Error("expected error when no older version is kept")
+	}
+
+	if err := updater.Pin(installed[2]); err != nil {
+		t.Fatalf("Pin() failed: %v", err)
+	}
+	if got := activeProviderYAML(t, destDir); got != releases[2] {
+		t.Errorf("expected third release after pin, got %q", got)
+	}
+	if err := updater.Pin("v9.9.9"); err == nil {
+		t.Error("expected error pinning a version that is not installed")
+	}
+
+	if err := updater.Unpin(); err != nil {
+		t.Fatalf("Unpin() failed: %v", err)
+	}
+	if readMetadata(filepath.Join(destDir, versionFile)).Pinned {
+		t.Error("expected metadata to be unpinned")
+	}
+}
+
+func TestUpdaterRejectsInvalidBundle(t *testing.T) {
+	sourceDir := t.TempDir()
+	destDir := t.TempDir()
+
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: NewDirSource(sourceDir)})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	writeProviderTree(t, sourceDir, testProviderYAML)
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+
+	broken := strings.Replace(testProviderYAML, "api_key: MIRROR_API_KEY\n", "", 1)
+	writeProviderTree(t, sourceDir, broken)
+	if err := updater.Update(context.Background()); err == nil {
+		t.Fatal("expected validation error for invalid bundle")
+	}
+
+	if got := activeProviderYAML(t, destDir); got != testProviderYAML {
+		t.Errorf("expected previous release to stay active, got %q", got)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(destDir, versionsDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected staging dir to be cleaned up, got %d entries", len(entries))
+	}
+}
+
+func TestUpdaterReinstallKeepsActiveVersion(t *testing.T) {
+	destDir := t.TempDir()
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: NewDirSource(t.TempDir())})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	bundle := &Bundle{Files: map[string][]byte{
+		"providers/mirror/provider.yaml":            []byte(testProviderYAML),
+		"providers/mirror/models/mirror-model.yaml": []byte(testModelYAML),
+	}}
+	metadata := Metadata{Version: "v1.0.0"}
+
+	installed, err := updater.stage(bundle, metadata)
+	if err != nil {
+		t.Fatalf("stage() failed: %v", err)
+	}
+	if err := updater.activate(installed, false); err != nil {
+		t.Fatalf("activate() failed: %v", err)
+	}
+	active := filepath.Join(activeProvidersDir(destDir), "mirror", providerYAML)
+	before, err := os.Stat(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := updater.stage(bundle, metadata); err != nil {
+		t.Fatalf("stage() reinstall failed: %v", err)
+	}
+	after, err := os.Stat(active)
+	if err != nil {
+		t.Fatalf("active version removed by reinstall: %v", err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("expected a valid installed version to be kept in place")
+	}
+
+	if err := os.Remove(filepath.Join(activeProvidersDir(destDir), "mirror", "models", "mirror-model.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(active, []byte("name: [broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := updater.stage(bundle, metadata); err != nil {
+		t.Fatalf("stage() repair failed: %v", err)
+	}
+	if got := activeProviderYAML(t, destDir); got != testProviderYAML {
+		t.Errorf("expected broken version to be replaced, got %q", got)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(destDir, versionsDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected staging dirs to be cleaned up, got %d entries", len(entries))
+	}
+}
+
+func TestUpdaterMigratesProvidersSymlink(t *testing.T) {
+	destDir := t.TempDir()
+	writeProviderTree(t, filepath.Join(destDir, versionsDir, "v0.1.0"), testProviderYAML)
+	if err := os.Symlink(filepath.Join(versionsDir, "v0.1.0", providersDir), filepath.Join(destDir, providersDir)); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: NewDirSource(t.TempDir())})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+	if err := updater.Pin("v0.1.0"); err != nil {
+		t.Fatalf("Pin() failed: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(destDir, providersDir)); !os.IsNotExist(err) {
+		t.Errorf("expected providers symlink to be removed, got %v", err)
+	}
+	if got := activeProviderYAML(t, destDir); got != testProviderYAML {
+		t.Errorf("expected symlinked version to stay active, got %q", got)
+	}
+}
+
+func TestUpdaterMigratesLegacyProvidersDir(t *testing.T) {
+	destDir := t.TempDir()
+	writeProviderTree(t, destDir, testProviderYAML)
+	if err := writeMetadata(filepath.Join(destDir, versionFile), Metadata{Version: "v0.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	updater, err := NewUpdaterWithConfig(UpdaterConfig{DestDir: destDir, Source: NewDirSource(t.TempDir())})
+	if err != nil {
+		t.Fatalf("NewUpdaterWithConfig() failed: %v", err)
+	}
+
+	versions, err := updater.Versions()
+	if err != nil {
+		t.Fatalf("Versions() failed: %v", err)
+	}
+	if len(versions) != 0 {
+		t.Fatalf("expected no versions before migration, got %d", len(versions))
+	}
+
+	if err := updater.Pin("v0.1.0"); err != nil {
+		t.Fatalf("Pin() failed: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(destDir, providersDir)); !os.IsNotExist(err) {
+		t.Errorf("expected legacy providers dir to be moved, got %v", err)
+	}
+	if got := activeVersion(destDir); got != "v0.1.0" {
+		t.Errorf("expected current to point at v0.1.0, got %q", got)
+	}
+	if got := activeProviderYAML(t, destDir); got != testProviderYAML {
+		t.Errorf("expected migrated provider, got %q", got)
+	}
+}
+
+func TestVersionDirName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "v1.2.3", want: "v1.2.3"},
+		{input: "sha256:abc", want: "sha256-abc"},
+		{input: "../../etc", want: "etc"},
+		{input: "", want: legacyVersionName},
+	}
+
+	for _, tt := range tests {
+		if got := versionDirName(tt.input); got != tt.want {
+			t.Errorf("versionDirName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
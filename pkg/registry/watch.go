@@ -0,0 +1,179 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const DefaultWatchInterval = 2 * time.Second
+
+type ReloadReason string
+
+const (
+	ReloadUpdate   ReloadReason = "update"
+	ReloadRollback ReloadReason = "rollback"
+	ReloadWatch    ReloadReason = "watch"
+	// ReloadDiscovery follows a change in the models a DiscoverySource lists.
+	ReloadDiscovery ReloadReason = "discovery"
+)
+
+// ReloadEvent is passed to Options.OnReload after the registry reloaded its
+// data, or failed to. On failure the previous data stays in use.
+type ReloadEvent struct {
+	Reason  ReloadReason
+	Version string
+	Err     error
+}
+
+// watchLoop reloads once changes to the cache or overrides dirs have settled
+// for an interval, polling where notifications cannot be set up.
+func (r *Registry) watchLoop(interval time.Duration, loaded string) {
+	defer r.wg.Done()
+
+	watcher, err := r.newWatcher()
+	if err != nil {
+		slog.Warn("file notifications unavailable, polling for changes", "error", err)
+		r.pollLoop(interval, loaded)
+		return
+	}
+	defer watcher.Close()
+
+	settle := time.NewTimer(interval)
+	defer settle.Stop()
+
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			// New directories, such as a newly activated version, need
+			// watches of their own.
+			_ = r.addWatches(watcher)
+			if !settle.Stop() {
+				select {
+				case <-settle.C:
+				default:
+				}
+			}
+			settle.Reset(interval)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("file watch failed", "error", err)
+		case <-settle.C:
+			if current := r.fingerprint(); current != loaded {
+				loaded = current
+				r.notify(ReloadWatch, r.reload())
+			}
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Registry) newWatcher() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.addWatches(watcher); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// addWatches watches the config dir, for the current pointer file, and every
+// directory of the active cache version and the overrides tree.
+func (r *Registry) addWatches(watcher *fsnotify.Watcher) error {
+	var trees []string
+	if r.configDir != "" {
+		if err := watcher.Add(r.configDir); err != nil {
+			return err
+		}
+		cacheDir := activeProvidersDir(r.configDir)
+		if stat, err := os.Stat(cacheDir); err == nil && stat.IsDir() {
+			trees = append(trees, cacheDir)
+		}
+	}
+	if r.overridesDir != "" {
+		trees = append(trees, r.overridesDir)
+	}
+
+	for _, tree := range trees {
+		err := filepath.WalkDir(tree, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			return watcher.Add(path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) pollLoop(interval time.Duration, loaded string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := loaded
+
+	for {
+		select {
+		case <-ticker.C:
+			current := r.fingerprint()
+			if current != previous {
+				previous = current
+				continue
+			}
+			if current == loaded {
+				continue
+			}
+
+			loaded = current
+			r.notify(ReloadWatch, r.reload())
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// fingerprint summarizes the watched trees by path, size and modification
+// time, plus the active version the current pointer file names.
+func (r *Registry) fingerprint() string {
+	h := sha256.New()
+
+	var cacheDir string
+	if r.configDir != "" {
+		cacheDir = activeProvidersDir(r.configDir)
+		fmt.Fprintf(h, "%s\n", activeVersion(r.configDir))
+	}
+
+	for _, dir := range []string{cacheDir, r.overridesDir} {
+		if dir == "" {
+			continue
+		}
+		_ = fs.WalkDir(os.DirFS(dir), ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info, err := d.Info(); err == nil && !d.IsDir() {
+				fmt.Fprintf(h, "%s %s %d %d\n", dir, path, info.Size(), info.ModTime().UnixNano())
+			}
+			return nil
+		})
+	}
+
+	return string(h.Sum(nil))
+}
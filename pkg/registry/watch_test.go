@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoaderOverrides(t *testing.T) {
+	overridesDir := t.TempDir()
+	writeFile(t, filepath.Join(overridesDir, "openai", providerYAML), "name: openai\nbase_url: https://proxy.example.com/v1\n")
+	writeFile(t, filepath.Join(overridesDir, "anthropic", "models", "local.yaml"), testModelYAML)
+	writeFile(t, filepath.Join(overridesDir, "mirror", providerYAML), testProviderYAML)
+	writeFile(t, filepath.Join(overridesDir, "orphan", "models", "orphan.yaml"), testModelYAML)
+
+	loader := NewLoader(t.TempDir())
+	loader.SetOverridesDir(overridesDir)
+
+	providers, err := loader.LoadStrict()
+	if err != nil {
+		t.Fatalf("LoadStrict() failed: %v", err)
+	}
+
+	openai := providers["openai"]
+	if openai.BaseURL != "https://proxy.example.com/v1" {
+		t.Errorf("expected overridden base_url, got %q", openai.BaseURL)
+	}
+	if openai.APIKey == "" || len(openai.Models) == 0 {
+		t.Error("expected embedded api_key and models to survive the override")
+	}
+	if model := providers["anthropic"].Models["mirror-model"]; model == nil || model.Provider != providers["anthropic"] {
+		t.Error("expected model-only override to attach to the embedded provider")
+	}
+	if providers["mirror"] == nil {
+		t.Error("expected new provider from overrides")
+	}
+	if providers["orphan"] != nil {
+		t.Error("expected models without a provider to be ignored")
+	}
+}
+
+func waitReload(t *testing.T, events <-chan ReloadEvent) ReloadEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+		return ReloadEvent{}
+	}
+}
+
+func TestWatchReloadsOverrides(t *testing.T) {
+	overridesDir := t.TempDir()
+	events := make(chan ReloadEvent, 10)
+
+	reg, err := New(Options{
+		ConfigDir:     t.TempDir(),
+		OverridesDir:  overridesDir,
+		Watch:         true,
+		WatchInterval: 20 * time.Millisecond,
+		OnReload:      func(event ReloadEvent) { events <- event },
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	writeFile(t, filepath.Join(overridesDir, "openai", providerYAML), "name: openai\ndescription: edited\n")
+
+	event := waitReload(t, events)
+	if event.Reason != ReloadWatch || event.Err != nil {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if got := reg.Provider("openai").Description; got != "edited" {
+		t.Errorf("expected reloaded description, got %q", got)
+	}
+
+	writeFile(t, filepath.Join(overridesDir, "openai", providerYAML), "name: openai\nauth_scheme:\n  type: cookie\n")
+
+	event = waitReload(t, events)
+	if event.Err == nil {
+		t.Fatal("expected validation error for invalid override")
+	}
+	if got := reg.Provider("openai").Description; got != "edited" {
+		t.Errorf("expected previous data to be kept, got %q", got)
+	}
+}
+
+func TestWatchPollsWithoutOverridesDir(t *testing.T) {
+	overridesDir := filepath.Join(t.TempDir(), "overrides")
+	events := make(chan ReloadEvent, 10)
+
+	reg, err := New(Options{
+		ConfigDir:     t.TempDir(),
+		OverridesDir:  overridesDir,
+		Watch:         true,
+		WatchInterval: 20 * time.Millisecond,
+		OnReload:      func(event ReloadEvent) { events <- event },
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	// The missing dir cannot be watched, so changes are found by polling.
+	writeFile(t, filepath.Join(overridesDir, "openai", providerYAML), "name: openai\ndescription: created\n")
+
+	event := waitReload(t, events)
+	if event.Err != nil {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if got := reg.Provider("openai").Description; got != "created" {
+		t.Errorf("expected reloaded description, got %q", got)
+	}
+}
+
+func TestOnReloadAfterUpdate(t *testing.T) {
+	sourceDir := t.TempDir()
+	writeProviderTree(t, sourceDir, testProviderYAML)
+
+	var events []ReloadEvent
+	reg, err := New(Options{
+		ConfigDir:    t.TempDir(),
+		UpdateSource: NewDirSource(sourceDir),
+		OnReload:     func(event ReloadEvent) { events = append(events, event) },
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer reg.Close()
+
+	if err := reg.ForceUpdate(); err != nil {
+		t.Fatalf("ForceUpdate() failed: %v", err)
+	}
+	if err := reg.Rollback(); err == nil {
+		t.Fatal("expected rollback to fail with a single version")
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Reason != ReloadUpdate || events[0].Err != nil || events[0].Version == "" {
+		t.Errorf("unexpected update event: %+v", events[0])
+	}
+	if events[1].Reason != ReloadRollback || events[1].Err == nil {
+		t.Errorf("unexpected rollback event: %+v", events[1])
+	}
+}